@@ -0,0 +1,79 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanchegoclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+
+	cjson "github.com/ava-labs/avalanchego/utils/json"
+)
+
+// Atomic transaction statuses reported by the C Chain
+const (
+	AtomicTxAccepted   = "Accepted"
+	AtomicTxProcessing = "Processing"
+	AtomicTxDropped    = "Dropped"
+	AtomicTxUnknown    = "Unknown"
+)
+
+// GetAtomicTxStatusReply defines the GetAtomicTxStatus replies returned from the C Chain API
+type GetAtomicTxStatusReply struct {
+	Status string `json:"status"`
+}
+
+// CChainAtomicClient covers the C Chain avax endpoints that are missing from evm.Client
+type CChainAtomicClient struct {
+	requester rpc.EndpointRequester
+}
+
+// NewCChainAtomicClient returns a CChainAtomicClient for the C Chain of the node at [uri]
+func NewCChainAtomicClient(uri string, requestTimeout time.Duration) *CChainAtomicClient {
+	return &CChainAtomicClient{
+		requester: rpc.NewEndpointRequester(uri, fmt.Sprintf("/ext/bc/%s/avax", CChain), "avax", requestTimeout),
+	}
+}
+
+// GetAtomicTxStatus returns the status of the atomic (import/export) transaction [txID]
+func (c *CChainAtomicClient) GetAtomicTxStatus(txID ids.ID) (string, error) {
+	res := &GetAtomicTxStatusReply{}
+	err := c.requester.SendRequest("getAtomicTxStatus", &api.JSONTxID{
+		TxID: txID,
+	}, res)
+	return res.Status, err
+}
+
+// GetAtomicUTXOs returns the byte representation of the UTXOs exported to the C Chain from [sourceChain]
+// and controlled by [addrs], evm.Client.GetUTXOs can't set the source chain
+func (c *CChainAtomicClient) GetAtomicUTXOs(addrs []string, sourceChain string, limit uint32, startAddress, startUTXOID string) ([][]byte, api.Index, error) {
+	res := &api.GetUTXOsReply{}
+	err := c.requester.SendRequest("getUTXOs", &api.GetUTXOsArgs{
+		Addresses:   addrs,
+		SourceChain: sourceChain,
+		Limit:       cjson.Uint32(limit),
+		StartIndex: api.Index{
+			Address: startAddress,
+			UTXO:    startUTXOID,
+		},
+		Encoding: formatting.Hex,
+	}, res)
+	if err != nil {
+		return nil, api.Index{}, err
+	}
+
+	utxos := make([][]byte, len(res.UTXOs))
+	for i, utxo := range res.UTXOs {
+		utxoBytes, err := formatting.Decode(formatting.Hex, utxo)
+		if err != nil {
+			return nil, api.Index{}, err
+		}
+		utxos[i] = utxoBytes
+	}
+	return utxos, res.EndIndex, nil
+}
@@ -5,6 +5,7 @@ package avalanchegoclient
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
@@ -23,23 +24,31 @@ import (
 // Chain names
 const (
 	XChain = "X"
+	PChain = "P"
 	CChain = "C"
 )
 
+// ethClientPoolSize is the number of C Chain connections pooled by the ConcurrentEthClient
+const ethClientPoolSize = 4
+
 // Client is a general client for avalanche
 type Client struct {
 	admin              *admin.Client
 	xChain             *avm.Client
 	health             *health.Client
+	healthReports      *HealthReportsClient
 	info               *info.Client
 	ipcs               *ipcs.Client
 	keystore           *keystore.Client
 	platform           *platformvm.Client
 	cChain             *evm.Client
+	cChainAtomic       *CChainAtomicClient
 	cChainEth          *ethclient.Client
 	cChaiConcurrentEth *ConcurrentEthClient
+	ethLock            sync.Mutex
 	ipAddr             string
 	port               int
+	txRecorder         TxRecorder
 }
 
 // NewClient returns a Client for interacting with the Chain endpoints
@@ -56,13 +65,15 @@ func NewClient(ipAddr string, port int, requestTimeout time.Duration) *Client {
 		admin:              admin.NewClient(uri, requestTimeout),
 		xChain:             avm.NewClient(uri, XChain, requestTimeout),
 		health:             health.NewClient(uri, requestTimeout),
+		healthReports:      NewHealthReportsClient(uri, requestTimeout),
 		info:               info.NewClient(uri, requestTimeout),
 		ipcs:               ipcs.NewClient(uri, requestTimeout),
 		keystore:           keystore.NewClient(uri, requestTimeout),
 		platform:           platformvm.NewClient(uri, requestTimeout),
 		cChain:             evm.NewCChainClient(uri, requestTimeout),
+		cChainAtomic:       NewCChainAtomicClient(uri, requestTimeout),
 		cChainEth:          cClient,
-		cChaiConcurrentEth: NewConcurrentEthClient(cClient),
+		cChaiConcurrentEth: NewConcurrentEthClient(),
 	}
 }
 
@@ -81,8 +92,21 @@ func (c *Client) CChainAPI() *evm.Client {
 	return c.cChain
 }
 
-// CChainEthAPI
+// CChainAtomicAPI ...
+func (c *Client) CChainAtomicAPI() *CChainAtomicClient {
+	return c.cChainAtomic
+}
+
+// CChainEthAPI returns the C Chain websocket client, it's dialed again if the connection wasn't made yet
 func (c *Client) CChainEthAPI() *ethclient.Client {
+	c.ethLock.Lock()
+	defer c.ethLock.Unlock()
+
+	return c.cChainEthAPI()
+}
+
+// cChainEthAPI is CChainEthAPI, the caller must hold ethLock
+func (c *Client) cChainEthAPI() *ethclient.Client {
 	var err error
 	var cClient *ethclient.Client
 	if c.cChainEth == nil {
@@ -104,14 +128,32 @@ func (c *Client) CChainEthAPI() *ethclient.Client {
 }
 
 // CChaiConcurrentEth wraps the ethclient.Client in a concurrency-safe implementation
+// pooling up to ethClientPoolSize connections, the first one being the CChainEthAPI one
 func (c *Client) CChaiConcurrentEth() *ConcurrentEthClient {
-	if c.cChainEth == nil || c.cChaiConcurrentEth.client == nil {
-		c.cChaiConcurrentEth = NewConcurrentEthClient(c.CChainEthAPI())
+	c.ethLock.Lock()
+	defer c.ethLock.Unlock()
+
+	if c.cChainEth == nil || c.cChaiConcurrentEth.Size() == 0 {
+		c.connectEthPool()
 	}
 
 	return c.cChaiConcurrentEth
 }
 
+// connectEthPool replaces the C Chain connection pool, the caller must hold ethLock
+func (c *Client) connectEthPool() {
+	clients := []*ethclient.Client{c.cChainEthAPI()}
+	for i := 1; i < ethClientPoolSize; i++ {
+		cClient, err := ethclient.Dial(fmt.Sprintf("ws://%s:%d/ext/bc/C/ws", c.ipAddr, c.port))
+		if err != nil {
+			logrus.Debugf("The C Chain connection pool is limited to %d connections: %v", len(clients), err)
+			break
+		}
+		clients = append(clients, cClient)
+	}
+	c.cChaiConcurrentEth = NewConcurrentEthClient(clients...)
+}
+
 // InfoAPI ...
 func (c *Client) InfoAPI() *info.Client {
 	return c.info
@@ -122,6 +164,11 @@ func (c *Client) HealthAPI() *health.Client {
 	return c.health
 }
 
+// HealthReportsAPI ...
+func (c *Client) HealthReportsAPI() *HealthReportsClient {
+	return c.healthReports
+}
+
 // IpcsAPI ...
 func (c *Client) IpcsAPI() *ipcs.Client {
 	return c.ipcs
@@ -137,8 +184,21 @@ func (c *Client) AdminAPI() *admin.Client {
 	return c.admin
 }
 
+// Reconnect replaces the C Chain connections, e.g. after the node restarted
+// the old connections are closed once their in-flight requests are done
 func (c *Client) Reconnect() *Client {
+	c.ethLock.Lock()
+	defer c.ethLock.Unlock()
+
+	oldPool, oldEth := c.cChaiConcurrentEth, c.cChainEth
 	c.cChainEth = nil
-	c.CChaiConcurrentEth()
+	c.connectEthPool()
+
+	if oldPool.Size() > 0 {
+		// the pool holds the old CChainEthAPI connection
+		oldPool.Close()
+	} else if oldEth != nil {
+		oldEth.Close()
+	}
 	return c
 }
@@ -5,34 +5,130 @@ package avalanchegoclient
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"sync"
 
+	"github.com/ava-labs/coreth"
 	"github.com/ava-labs/coreth/core/types"
 	"github.com/ava-labs/coreth/ethclient"
 	"github.com/ethereum/go-ethereum/common"
 )
 
+var (
+	errNoEthConnection = errors.New("the C Chain client has no connection")
+	errEthClientClosed = errors.New("the C Chain client is closed")
+)
+
 // ConcurrentEthClient is a concurrency-safe implementation
-// of ethclient.Client that allows for multiple concurrent
-// requests to be made to a single *services.Client.
+// of ethclient.Client backed by a pool of connections to a single node.
+// Each request takes a free connection for its duration, so independent
+// requests don't wait for each other until every connection is busy.
 type ConcurrentEthClient struct {
-	client *ethclient.Client
-	lock   sync.Mutex
+	clients []*ethclient.Client
+	free    chan *ethclient.Client
+
+	// lock orders the releases with Close, closed is closed by Close
+	lock      sync.Mutex
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
-// NewConcurrentEthClient ...
-func NewConcurrentEthClient(client *ethclient.Client) *ConcurrentEthClient {
-	return &ConcurrentEthClient{
-		client: client,
+// NewConcurrentEthClient returns a ConcurrentEthClient pooling [clients], nil clients are ignored
+func NewConcurrentEthClient(clients ...*ethclient.Client) *ConcurrentEthClient {
+	c := &ConcurrentEthClient{closed: make(chan struct{})}
+	for _, client := range clients {
+		if client != nil {
+			c.clients = append(c.clients, client)
+		}
 	}
+	c.free = make(chan *ethclient.Client, len(c.clients))
+	for _, client := range c.clients {
+		c.free <- client
+	}
+	return c
 }
 
-// Close terminates the client's connection.
-func (c *ConcurrentEthClient) Close() {
+// Size returns the number of connections of the pool
+func (c *ConcurrentEthClient) Size() int {
+	return len(c.clients)
+}
+
+// acquire waits for a free connection until [ctx] is done or the client is closed,
+// it must be released once the request is done
+func (c *ConcurrentEthClient) acquire(ctx context.Context) (*ethclient.Client, error) {
+	if len(c.clients) == 0 {
+		return nil, errNoEthConnection
+	}
+	select {
+	case <-c.closed:
+		return nil, errEthClientClosed
+	default:
+	}
+
+	select {
+	case client := <-c.free:
+		return client, nil
+	case <-c.closed:
+		return nil, errEthClientClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release returns [client] to the pool, or closes it if the pool was closed while it was in use
+func (c *ConcurrentEthClient) release(client *ethclient.Client) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	c.client.Close()
+
+	select {
+	case <-c.closed:
+		client.Close()
+	default:
+		c.free <- client
+	}
+}
+
+// Close terminates the client's connections.
+// The free connections are closed right away, the ones in use once their request is done.
+// The requests waiting for a connection fail, Close can be called more than once.
+func (c *ConcurrentEthClient) Close() {
+	c.closeOnce.Do(func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		close(c.closed)
+		for {
+			select {
+			case client := <-c.free:
+				client.Close()
+			default:
+				return
+			}
+		}
+	})
+}
+
+// ChainID retrieves the current chain ID for transaction replay protection.
+func (c *ConcurrentEthClient) ChainID(ctx context.Context) (*big.Int, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.ChainID(ctx)
+}
+
+// NetworkID returns the network ID (also known as the chain ID) for this chain.
+func (c *ConcurrentEthClient) NetworkID(ctx context.Context) (*big.Int, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.NetworkID(ctx)
 }
 
 // SendTransaction injects a signed transaction into the pending pool for execution.
@@ -40,28 +136,179 @@ func (c *ConcurrentEthClient) Close() {
 // If the transaction was a contract creation use the TransactionReceipt method to get the
 // contract address after the transaction has been mined.
 func (c *ConcurrentEthClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.release(client)
 
-	return c.client.SendTransaction(ctx, tx)
+	return client.SendTransaction(ctx, tx)
 }
 
 // TransactionReceipt returns the receipt of a transaction by transaction hash.
 // Note that the receipt is not available for pending transactions.
 func (c *ConcurrentEthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
 
-	return c.client.TransactionReceipt(ctx, txHash)
+	return client.TransactionReceipt(ctx, txHash)
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (c *ConcurrentEthClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer c.release(client)
+
+	return client.TransactionByHash(ctx, hash)
 }
 
 // BalanceAt returns the wei balance of the given account.
 // The block number can be nil, in which case the balance is taken from the latest known block.
 func (c *ConcurrentEthClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.BalanceAt(ctx, account, blockNumber)
+}
+
+// StorageAt returns the value of key in the contract storage of the given account.
+// The block number can be nil, in which case the value is taken from the latest known block.
+func (c *ConcurrentEthClient) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.StorageAt(ctx, account, key, blockNumber)
+}
+
+// CodeAt returns the contract code of the given account.
+// The block number can be nil, in which case the code is taken from the latest known block.
+func (c *ConcurrentEthClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.CodeAt(ctx, account, blockNumber)
+}
+
+// NonceAt returns the account nonce of the given account.
+// The block number can be nil, in which case the nonce is taken from the latest known block.
+func (c *ConcurrentEthClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer c.release(client)
+
+	return client.NonceAt(ctx, account, blockNumber)
+}
+
+// SuggestGasPrice retrieves the currently suggested gas price to allow a timely
+// execution of a transaction.
+func (c *ConcurrentEthClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.SuggestGasPrice(ctx)
+}
+
+// EstimateGas tries to estimate the gas needed to execute a specific transaction based on
+// the current pending state of the backend blockchain.
+func (c *ConcurrentEthClient) EstimateGas(ctx context.Context, msg coreth.CallMsg) (uint64, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer c.release(client)
+
+	return client.EstimateGas(ctx, msg)
+}
+
+// CallContract executes a message call transaction, which is directly executed in the VM
+// of the node, but never mined into the blockchain.
+// The block number can be nil, in which case the call is executed on the latest known block.
+func (c *ConcurrentEthClient) CallContract(ctx context.Context, msg coreth.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.CallContract(ctx, msg, blockNumber)
+}
+
+// FilterLogs executes a filter query.
+func (c *ConcurrentEthClient) FilterLogs(ctx context.Context, q coreth.FilterQuery) ([]types.Log, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
 
-	return c.client.BalanceAt(ctx, account, blockNumber)
+	return client.FilterLogs(ctx, q)
+}
+
+// SubscribeFilterLogs subscribes to the results of a streaming filter query.
+// The subscription keeps its connection, which stays available to the other requests.
+func (c *ConcurrentEthClient) SubscribeFilterLogs(ctx context.Context, q coreth.FilterQuery, ch chan<- types.Log) (coreth.Subscription, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.SubscribeFilterLogs(ctx, q, ch)
+}
+
+// SubscribeNewHead subscribes to notifications about the current blockchain head on the given channel.
+// The subscription keeps its connection, which stays available to the other requests.
+func (c *ConcurrentEthClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (coreth.Subscription, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.SubscribeNewHead(ctx, ch)
+}
+
+// HeaderByNumber returns a block header from the current canonical chain. If number is
+// nil, the latest known header is returned.
+func (c *ConcurrentEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.HeaderByNumber(ctx, number)
+}
+
+// HeaderByHash returns the block header with the given hash.
+func (c *ConcurrentEthClient) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.HeaderByHash(ctx, hash)
 }
 
 // BlockByNumber returns a block from the current canonical chain. If number is nil, the
@@ -70,16 +317,33 @@ func (c *ConcurrentEthClient) BalanceAt(ctx context.Context, account common.Addr
 // Note that loading full blocks requires two requests. Use HeaderByNumber
 // if you don't need all transactions or uncle headers.
 func (c *ConcurrentEthClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
 
-	return c.client.BlockByNumber(ctx, number)
+	return client.BlockByNumber(ctx, number)
+}
+
+// BlockByHash returns the given full block.
+func (c *ConcurrentEthClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(client)
+
+	return client.BlockByHash(ctx, hash)
 }
 
 // BlockNumber returns the most recent block number
 func (c *ConcurrentEthClient) BlockNumber(ctx context.Context) (uint64, error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	client, err := c.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer c.release(client)
 
-	return c.client.BlockNumber(ctx)
+	return client.BlockNumber(ctx)
 }
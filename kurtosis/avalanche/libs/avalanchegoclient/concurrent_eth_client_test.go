@@ -0,0 +1,99 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanchegoclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/coreth/ethclient"
+)
+
+// newTestEthClient returns an HTTP client, it doesn't connect until a request is made
+func newTestEthClient(t *testing.T) *ethclient.Client {
+	client, err := ethclient.Dial("http://127.0.0.1:1/ext/bc/C/rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestConcurrentEthClientAcquire(t *testing.T) {
+	pool := NewConcurrentEthClient(newTestEthClient(t))
+	defer pool.Close()
+
+	client, err := pool.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every connection is busy, the request gives up with its context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	pool.release(client)
+	if _, err := pool.acquire(context.Background()); err != nil {
+		t.Fatalf("expected the released connection to be free, got %v", err)
+	}
+}
+
+func TestConcurrentEthClientNoConnection(t *testing.T) {
+	pool := NewConcurrentEthClient(nil)
+	if _, err := pool.acquire(context.Background()); err != errNoEthConnection {
+		t.Fatalf("expected %v, got %v", errNoEthConnection, err)
+	}
+	pool.Close()
+}
+
+func TestConcurrentEthClientClose(t *testing.T) {
+	pool := NewConcurrentEthClient(newTestEthClient(t), newTestEthClient(t))
+
+	inUse, err := pool.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	waiting := make(chan error)
+	go func() {
+		_, err := pool.acquire(context.Background())
+		waiting <- err
+	}()
+
+	// Close doesn't wait for the connections in use and fails the waiting requests
+	closed := make(chan struct{})
+	go func() {
+		pool.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close waited for the connections in use")
+	}
+	select {
+	case err := <-waiting:
+		if err != errEthClientClosed {
+			t.Fatalf("expected %v, got %v", errEthClientClosed, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the waiting request wasn't failed by Close")
+	}
+
+	// the connections released after Close are closed, not returned to the pool
+	pool.release(inUse)
+	if len(pool.free) != 0 {
+		t.Fatalf("expected no free connection after Close, got %d", len(pool.free))
+	}
+	if _, err := pool.acquire(context.Background()); err != errEthClientClosed {
+		t.Fatalf("expected %v, got %v", errEthClientClosed, err)
+	}
+	pool.Close()
+}
@@ -0,0 +1,64 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanchegoclient
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/rpc"
+)
+
+// HealthCheckResult is the result of a single health check
+// Message and Error are left untyped as health.Client can't unmarshal the errors of failing checks
+type HealthCheckResult struct {
+	Message            interface{} `json:"message,omitempty"`
+	Error              interface{} `json:"error,omitempty"`
+	Timestamp          time.Time   `json:"timestamp"`
+	ContiguousFailures int64       `json:"contiguousFailures"`
+	TimeOfFirstFailure *time.Time  `json:"timeOfFirstFailure"`
+}
+
+// HealthReply defines the replies returned from the health API
+type HealthReply struct {
+	Checks  map[string]HealthCheckResult `json:"checks"`
+	Healthy bool                         `json:"healthy"`
+}
+
+// FailingChecks returns a description of each failing check, sorted by check name
+func (r *HealthReply) FailingChecks() []string {
+	var failing []string
+	for name, result := range r.Checks {
+		if result.Error == nil {
+			continue
+		}
+		description := fmt.Sprintf("%s: %v", name, result.Error)
+		if result.Message != nil {
+			description += fmt.Sprintf(" (%v)", result.Message)
+		}
+		failing = append(failing, fmt.Sprintf("%s, failing %d times", description, result.ContiguousFailures))
+	}
+	sort.Strings(failing)
+	return failing
+}
+
+// HealthReportsClient reads the health API reports, including the ones of failing checks
+type HealthReportsClient struct {
+	requester rpc.EndpointRequester
+}
+
+// NewHealthReportsClient returns a HealthReportsClient for the node at [uri]
+func NewHealthReportsClient(uri string, requestTimeout time.Duration) *HealthReportsClient {
+	return &HealthReportsClient{
+		requester: rpc.NewEndpointRequester(uri, "/ext/health", "health", requestTimeout),
+	}
+}
+
+// GetLiveness returns the result of the node health checks
+func (c *HealthReportsClient) GetLiveness() (*HealthReply, error) {
+	res := &HealthReply{}
+	err := c.requester.SendRequest("getLiveness", struct{}{}, res)
+	return res, err
+}
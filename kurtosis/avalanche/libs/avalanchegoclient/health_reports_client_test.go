@@ -0,0 +1,28 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanchegoclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFailingChecks(t *testing.T) {
+	reply := &HealthReply{
+		Checks: map[string]HealthCheckResult{
+			"network":  {Message: map[string]interface{}{"connectedPeers": 0}, Error: "not connected to a minimum of 1 peer(s)", ContiguousFailures: 3},
+			"chains.P": {Error: "not bootstrapped", ContiguousFailures: 1},
+			"chains.X": {Message: "healthy"},
+			"chains.C": {},
+		},
+	}
+
+	expected := []string{
+		"chains.P: not bootstrapped, failing 1 times",
+		"network: not connected to a minimum of 1 peer(s) (map[connectedPeers:0]), failing 3 times",
+	}
+	if failing := reply.FailingChecks(); !reflect.DeepEqual(failing, expected) {
+		t.Fatalf("expected %q, got %q", expected, failing)
+	}
+}
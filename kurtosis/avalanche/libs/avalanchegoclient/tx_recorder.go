@@ -0,0 +1,34 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanchegoclient
+
+// TxOutcome is the outcome of a transaction issued through a Client
+type TxOutcome int
+
+// Transaction outcomes
+const (
+	TxIssueFailed TxOutcome = iota
+	TxAccepted
+	TxRejected
+	TxTimedOut
+)
+
+// TxRecorder records the transactions issued through a Client and their outcome, e.g. in a test report
+// a transaction may be awaited on several nodes, its [txID] tells them apart, it's empty for an issue failure
+type TxRecorder interface {
+	RecordTx(chain string, txID string, outcome TxOutcome)
+}
+
+// SetTxRecorder sets the TxRecorder of the transactions issued through the Client
+// It must be set before the Client is shared
+func (c *Client) SetTxRecorder(recorder TxRecorder) {
+	c.txRecorder = recorder
+}
+
+// RecordTx records the [outcome] of the [txID] transaction issued on [chain], if the Client has a TxRecorder
+func (c *Client) RecordTx(chain string, txID string, outcome TxOutcome) {
+	if c.txRecorder != nil {
+		c.txRecorder.RecordTx(chain, txID, outcome)
+	}
+}
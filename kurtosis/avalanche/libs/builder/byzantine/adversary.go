@@ -0,0 +1,173 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package byzantine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/txhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	helpers "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/codecs"
+)
+
+// Adversary plays the Byzantine nodes of a network: it issues conflicting and stale X Chain transactions
+// through them and through the honest nodes, and verifies the network still reaches a single decision
+type Adversary struct {
+	chainIDs constants.ChainIDs
+	txFee    uint64
+	codec    codec.Manager
+	// byzantine are the Byzantine nodes, honest every other node of the network, both sorted by ID
+	byzantine []*adversaryNode
+	honest    []*adversaryNode
+	// Timeout is how long a ConflictSet has to be decided on every node
+	Timeout time.Duration
+}
+
+type adversaryNode struct {
+	id     string
+	client *avalanchegoclient.Client
+}
+
+// ConflictSet is a set of X Chain transactions spending the same UTXO, at most one of them can be accepted
+type ConflictSet struct {
+	TxIDs   []ids.ID
+	txBytes [][]byte
+}
+
+// NewAdversary creates the Adversary of the Byzantine nodes of [definedNetwork], launched in [network]
+func NewAdversary(definedNetwork *networkbuilder.Network, network networks.Network) (*Adversary, error) {
+	avalancheNetwork := networksavalanche.Cast(network)
+	xCodec, err := helpers.CreateXChainCodec()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to create the X Chain codec")
+	}
+
+	adversary := &Adversary{
+		chainIDs: avalancheNetwork.GetGenesisConfig().ChainIDs,
+		txFee:    definedNetwork.GetTxFee(),
+		codec:    xCodec,
+		Timeout:  constants.TimeoutDuration,
+	}
+	adversary.byzantine, err = getAdversaryNodes(avalancheNetwork, definedNetwork.GetByzantineNodes())
+	if err != nil {
+		return nil, err
+	}
+	adversary.honest, err = getAdversaryNodes(avalancheNetwork, definedNetwork.GetHonestNodes())
+	if err != nil {
+		return nil, err
+	}
+	if len(adversary.byzantine) == 0 {
+		return nil, stacktrace.NewError("The network has no Byzantine node")
+	}
+	if len(adversary.honest) == 0 {
+		return nil, stacktrace.NewError("The network has no honest node")
+	}
+	return adversary, nil
+}
+
+// getAdversaryNodes fetches the clients of [nodes] in [avalancheNetwork]
+func getAdversaryNodes(avalancheNetwork *networksavalanche.AvalancheNetwork, nodes []*networkbuilder.Node) ([]*adversaryNode, error) {
+	adversaryNodes := make([]*adversaryNode, 0, len(nodes))
+	for _, node := range nodes {
+		client, err := avalancheNetwork.GetNodeClient(node.ID)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to fetch the client of node %s", node.ID)
+		}
+		adversaryNodes = append(adversaryNodes, &adversaryNode{id: node.ID, client: client})
+	}
+	return adversaryNodes, nil
+}
+
+// ConflictingTxs builds [numTxs] transactions spending [utxo], owned by [privateKey] alone, to [to]
+// each transaction burns 1 more nAVAX than the previous one so they all have a different ID
+func (a *Adversary) ConflictingTxs(utxo *avax.UTXO, privateKey *crypto.PrivateKeySECP256K1R, to ids.ShortID, numTxs int) (*ConflictSet, error) {
+	if numTxs < 2 {
+		return nil, stacktrace.NewError("A conflict set needs at least 2 transactions, got %d", numTxs)
+	}
+	out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+	if !ok {
+		return nil, stacktrace.NewError("UTXO %s is not a transfer output", utxo.InputID())
+	}
+	if out.Amt <= a.txFee+uint64(numTxs) {
+		return nil, stacktrace.NewError("UTXO %s holds %d, not enough to pay the fee of %d conflicting transactions",
+			utxo.InputID(), out.Amt, numTxs)
+	}
+
+	set := &ConflictSet{}
+	for i := 0; i < numTxs; i++ {
+		tx, err := txhelper.CreateSingleUTXOTx(a.chainIDs, utxo, out.Amt, out.Amt-a.txFee-uint64(i), to, privateKey, a.codec)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to create conflicting transaction %d", i)
+		}
+		set.TxIDs = append(set.TxIDs, tx.ID())
+		set.txBytes = append(set.txBytes, tx.Bytes())
+	}
+	return set, nil
+}
+
+// IssueConflicting issues the transactions of [set] at the same time, each one to a different node
+// the first ones to the Byzantine nodes, the next ones to the honest nodes
+// Nodes may refuse a transaction, it fails only if none was issued
+func (a *Adversary) IssueConflicting(set *ConflictSet) error {
+	nodes := a.nodes()
+	start := make(chan struct{})
+	issued := make([]bool, len(set.TxIDs))
+	var wg sync.WaitGroup
+	for i := range set.TxIDs {
+		wg.Add(1)
+		go func(i int, node *adversaryNode) {
+			defer wg.Done()
+			<-start
+			if _, err := node.client.XChainAPI().IssueTx(set.txBytes[i]); err != nil {
+				logrus.Infof("Node %s refused conflicting transaction %s: %v", node.id, set.TxIDs[i], err)
+				return
+			}
+			issued[i] = true
+			logrus.Infof("Issued conflicting transaction %s to node %s.", set.TxIDs[i], node.id)
+		}(i, nodes[i%len(nodes)])
+	}
+	close(start)
+	wg.Wait()
+
+	for _, ok := range issued {
+		if ok {
+			return nil
+		}
+	}
+	return stacktrace.NewError("Every node refused the conflicting transactions")
+}
+
+// Replay issues again every transaction of the decided [set] to every node
+// the network must keep its decision, whether the nodes refuse the stale transactions or not
+func (a *Adversary) Replay(set *ConflictSet) {
+	for _, node := range a.nodes() {
+		for i, txBytes := range set.txBytes {
+			if _, err := node.client.XChainAPI().IssueTx(txBytes); err != nil {
+				logrus.Debugf("Node %s refused stale transaction %s: %v", node.id, set.TxIDs[i], err)
+				continue
+			}
+			logrus.Debugf("Node %s took stale transaction %s.", node.id, set.TxIDs[i])
+		}
+	}
+}
+
+// nodes returns the Byzantine nodes followed by the honest ones
+func (a *Adversary) nodes() []*adversaryNode {
+	nodes := make([]*adversaryNode, 0, len(a.byzantine)+len(a.honest))
+	nodes = append(nodes, a.byzantine...)
+	return append(nodes, a.honest...)
+}
@@ -0,0 +1,26 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package byzantine
+
+import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/palantir/stacktrace"
+)
+
+// VerifyConflictSet waits for [set] to be decided on every node, Byzantine or honest, and returns the ID of its
+// accepted transaction
+// It fails if no transaction, or more than one, is accepted network-wide, or if the nodes don't agree
+func (a *Adversary) VerifyConflictSet(set *ConflictSet) (ids.ID, error) {
+	clients := map[string]*avalanchegoclient.Client{}
+	for _, node := range a.nodes() {
+		clients[node.id] = node.client
+	}
+	acceptedTxID, _, err := chainhelper.XChain().AwaitConflictSetDecision(clients, set.TxIDs, a.Timeout)
+	if err != nil {
+		return ids.Empty, stacktrace.Propagate(err, "The network didn't reach a single decision on the conflict set")
+	}
+	return acceptedTxID, nil
+}
@@ -4,27 +4,168 @@
 package chainhelper
 
 import (
+	"context"
+	"math/big"
 	"time"
 
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/txhelper"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/coreth"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
 )
 
+// x2cRate is the conversion rate between the X Chain denomination (nAVAX, 9 decimals)
+// and the C Chain denomination (wei, 18 decimals)
+var x2cRate = big.NewInt(1000000000)
+
 // This helper automates some the most used functions in the CChain
 type CChainHelper struct {
 }
 
-// AwaitTransactionAcceptance waits for the [txID] to be accepted within [timeout]
+// AwaitTransactionAcceptance waits for the atomic (import/export) [txID] to be accepted within [timeout]
+// The outcome is recorded through [client]
 func (c *CChainHelper) AwaitTransactionAcceptance(client *avalanchegoclient.Client, txID ids.ID, timeout time.Duration) error {
 
-	// TODO replace when getTxStatus is added to the C Chain API
-	time.Sleep(timeout / 5)
-	return nil
+	for startTime := time.Now(); time.Since(startTime) < timeout; time.Sleep(time.Second) {
+		status, err := client.CChainAtomicAPI().GetAtomicTxStatus(txID)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get status.")
+		}
+		logrus.Tracef("Status for transaction %s: %s", txID, status)
+
+		if status == avalanchegoclient.AtomicTxAccepted {
+			client.RecordTx(avalanchegoclient.CChain, txID.String(), avalanchegoclient.TxAccepted)
+			return nil
+		}
+		if status == avalanchegoclient.AtomicTxDropped {
+			client.RecordTx(avalanchegoclient.CChain, txID.String(), avalanchegoclient.TxRejected)
+			return stacktrace.NewError("Transaction %s was %s", txID, status)
+		}
+	}
+	client.RecordTx(avalanchegoclient.CChain, txID.String(), avalanchegoclient.TxTimedOut)
+	return stacktrace.NewError("Timed out waiting for transaction %s to be accepted on the CChain.", txID)
+}
+
+// AwaitEthTransactionReceipt waits for the EVM transaction [txHash] to be mined within [timeout] and returns its receipt
+// A transaction that was mined but failed to execute returns an error, the outcome is recorded through [client]
+func (c *CChainHelper) AwaitEthTransactionReceipt(client *avalanchegoclient.Client, txHash common.Hash, timeout time.Duration) (*types.Receipt, error) {
+
+	for startTime := time.Now(); time.Since(startTime) < timeout; time.Sleep(time.Second) {
+		receipt, err := client.CChaiConcurrentEth().TransactionReceipt(context.Background(), txHash)
+		if err == coreth.NotFound {
+			logrus.Tracef("Receipt for transaction %s not found yet", txHash.Hex())
+			continue
+		}
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to get receipt.")
+		}
+
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			client.RecordTx(avalanchegoclient.CChain, txHash.Hex(), avalanchegoclient.TxRejected)
+			return receipt, stacktrace.NewError("Transaction %s failed in block %v", txHash.Hex(), receipt.BlockNumber)
+		}
+		client.RecordTx(avalanchegoclient.CChain, txHash.Hex(), avalanchegoclient.TxAccepted)
+		return receipt, nil
+	}
+	client.RecordTx(avalanchegoclient.CChain, txHash.Hex(), avalanchegoclient.TxTimedOut)
+	return nil, stacktrace.NewError("Timed out waiting for transaction %s to be accepted on the CChain.", txHash.Hex())
 }
 
-// CheckBalance validates the [address] balance is equal to [amount]
+// AwaitBlockNumber waits for the C Chain of [client] to reach the block [height] within [timeout]
+func (c *CChainHelper) AwaitBlockNumber(client *avalanchegoclient.Client, height uint64, timeout time.Duration) error {
+
+	for startTime := time.Now(); time.Since(startTime) < timeout; time.Sleep(time.Second) {
+		blockNumber, err := client.CChaiConcurrentEth().BlockNumber(context.Background())
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get the C Chain height.")
+		}
+		if blockNumber >= height {
+			return nil
+		}
+		logrus.Tracef("C Chain height %d, waiting for %d", blockNumber, height)
+	}
+	return stacktrace.NewError("Timed out waiting for the C Chain to reach block %d.", height)
+}
+
+// DeployContract deploys [bytecode], followed by its encoded constructor arguments, from the account of [privateKey]
+// and waits for its receipt within [timeout], it returns the address of the contract
+func (c *CChainHelper) DeployContract(client *avalanchegoclient.Client, privateKey *crypto.PrivateKeySECP256K1R, bytecode []byte, gas uint64, timeout time.Duration) (common.Address, *types.Receipt, error) {
+	ethClient := client.CChaiConcurrentEth()
+	ctx := context.Background()
+
+	chainID, err := ethClient.ChainID(ctx)
+	if err != nil {
+		return common.Address{}, nil, stacktrace.Propagate(err, "Failed to get the C Chain ID")
+	}
+	from := evm.GetEthAddress(privateKey)
+	nonce, err := ethClient.NonceAt(ctx, from, nil)
+	if err != nil {
+		return common.Address{}, nil, stacktrace.Propagate(err, "Failed to get the nonce of %s", from.Hex())
+	}
+	gasPrice, err := ethClient.SuggestGasPrice(ctx)
+	if err != nil {
+		return common.Address{}, nil, stacktrace.Propagate(err, "Failed to get the gas price")
+	}
+
+	tx, err := txhelper.CreateEthTx(chainID, nonce, txhelper.EthCall{Data: bytecode, Gas: gas}, gasPrice, privateKey)
+	if err != nil {
+		return common.Address{}, nil, stacktrace.Propagate(err, "Failed to create the contract creation")
+	}
+	if err := ethClient.SendTransaction(ctx, tx); err != nil {
+		client.RecordTx(avalanchegoclient.CChain, "", avalanchegoclient.TxIssueFailed)
+		return common.Address{}, nil, stacktrace.Propagate(err, "Failed to send the contract creation %s", tx.Hash().Hex())
+	}
+
+	receipt, err := c.AwaitEthTransactionReceipt(client, tx.Hash(), timeout)
+	if err != nil {
+		return common.Address{}, nil, stacktrace.Propagate(err, "Failed to deploy the contract")
+	}
+	logrus.Infof("Deployed contract %s using %d gas", receipt.ContractAddress.Hex(), receipt.GasUsed)
+	return receipt.ContractAddress, receipt, nil
+}
+
+// CheckBalance validates the [address] balance is equal to [expectedAmount]
+// [expectedAmount] is denominated in nAVAX, like in the X and P Chains, and converted to wei
 func (c *CChainHelper) CheckBalance(client *avalanchegoclient.Client, address string, assetID string, expectedAmount uint64) error {
-	panic("TODO")
+	if assetID != "AVAX" {
+		return stacktrace.NewError("Only AVAX balances can be checked on the CChain, got asset %s", assetID)
+	}
+	if !common.IsHexAddress(address) {
+		return stacktrace.NewError("Invalid CChain address %s", address)
+	}
+
+	return c.CheckBalanceWei(client, common.HexToAddress(address), NAVAXToWei(expectedAmount))
+}
+
+// CheckBalanceWei validates the [address] balance is equal to [expectedAmount] wei
+func (c *CChainHelper) CheckBalanceWei(client *avalanchegoclient.Client, address common.Address, expectedAmount *big.Int) error {
+	cBalance, err := client.CChaiConcurrentEth().BalanceAt(context.Background(), address, nil)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to retrieve C Chain balance.")
+	}
+
+	if cBalance.Cmp(expectedAmount) != 0 {
+		return stacktrace.NewError("Found unexpected C Chain Balance for address: %s. Expected: %v wei, found: %v wei",
+			address.Hex(), expectedAmount, cBalance)
+	}
+
+	return nil
+}
+
+// NAVAXToWei converts an [amount] of nAVAX into wei
+func NAVAXToWei(amount uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(amount), x2cRate)
+}
+
+// WeiToNAVAX converts an [amount] of wei into nAVAX, the remainder that can't be represented in nAVAX is dropped
+func WeiToNAVAX(amount *big.Int) uint64 {
+	return new(big.Int).Div(amount, x2cRate).Uint64()
 }
 
 // CChain is a helper to chain request to the correct VM
@@ -18,6 +18,7 @@ type PChainHelper struct {
 }
 
 // AwaitTransactionAcceptance waits for the [txID] to be committed within [timeout]
+// The outcome is recorded through [client]
 func (p *PChainHelper) AwaitTransactionAcceptance(client *avalanchegoclient.Client, txID ids.ID, timeout time.Duration) error {
 
 	for startTime := time.Now(); time.Since(startTime) < timeout; time.Sleep(time.Second) {
@@ -28,16 +29,37 @@ func (p *PChainHelper) AwaitTransactionAcceptance(client *avalanchegoclient.Clie
 		logrus.Tracef("Status for transaction: %s: %s", txID, status.Status)
 
 		if status.Status == platformvm.Committed {
+			client.RecordTx(avalanchegoclient.PChain, txID.String(), avalanchegoclient.TxAccepted)
 			return nil
 		}
 
 		if status.Status == platformvm.Dropped || status.Status == platformvm.Aborted {
+			client.RecordTx(avalanchegoclient.PChain, txID.String(), avalanchegoclient.TxRejected)
 			return stacktrace.NewError("Abandoned Tx: %s because it had status: %s. Reason: %s", txID, status.Status, status.Reason)
 		}
 	}
+	client.RecordTx(avalanchegoclient.PChain, txID.String(), avalanchegoclient.TxTimedOut)
 	return stacktrace.NewError("Timed out waiting for transaction %s to be accepted on the PChain.", txID)
 }
 
+// AwaitTransactionStall checks the [txID] is not decided for [duration], e.g. while the network lost its quorum
+func (p *PChainHelper) AwaitTransactionStall(client *avalanchegoclient.Client, txID ids.ID, duration time.Duration) error {
+
+	for startTime := time.Now(); time.Since(startTime) < duration; time.Sleep(time.Second) {
+		status, err := client.PChainAPI().GetTxStatus(txID, true)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get status")
+		}
+		logrus.Tracef("Status for transaction: %s: %s", txID, status.Status)
+
+		switch status.Status {
+		case platformvm.Committed, platformvm.Aborted, platformvm.Dropped:
+			return stacktrace.NewError("Transaction %s was %s on the PChain, expected it to stall", txID, status.Status)
+		}
+	}
+	return nil
+}
+
 // CheckBalance validates the [address] balance is equal to [amount]
 func (p *PChainHelper) CheckBalance(client *avalanchegoclient.Client, address string, amount uint64) error {
 
@@ -54,6 +76,31 @@ func (p *PChainHelper) CheckBalance(client *avalanchegoclient.Client, address st
 	return nil
 }
 
+// AwaitBlockchainBootstrapped waits for [blockchainID] to be validated by the node and bootstrapped within [timeout]
+func (p *PChainHelper) AwaitBlockchainBootstrapped(client *avalanchegoclient.Client, blockchainID ids.ID, timeout time.Duration) error {
+
+	for startTime := time.Now(); time.Since(startTime) < timeout; time.Sleep(time.Second) {
+		status, err := client.PChainAPI().GetBlockchainStatus(blockchainID.String())
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get blockchain status")
+		}
+		logrus.Tracef("Status for blockchain: %s: %s", blockchainID, status)
+
+		if status != platformvm.Validating {
+			continue
+		}
+
+		bootstrapped, err := client.InfoAPI().IsBootstrapped(blockchainID.String())
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get bootstrap status")
+		}
+		if bootstrapped {
+			return nil
+		}
+	}
+	return stacktrace.NewError("Timed out waiting for blockchain %s to be bootstrapped.", blockchainID)
+}
+
 // PChain is a helper to chain request to the correct VM
 func PChain() *PChainHelper {
 
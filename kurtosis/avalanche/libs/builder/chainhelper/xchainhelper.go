@@ -4,6 +4,9 @@
 package chainhelper
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
@@ -17,6 +20,7 @@ import (
 type XChainHelper struct{}
 
 // AwaitTransactionAcceptance waits for the [txID] to be accepted within [timeout]
+// The outcome is recorded through [client]
 func (x *XChainHelper) AwaitTransactionAcceptance(client *avalanchegoclient.Client, txID ids.ID, timeout time.Duration) error {
 
 	for startTime := time.Now(); time.Since(startTime) < timeout; time.Sleep(time.Second) {
@@ -26,15 +30,163 @@ func (x *XChainHelper) AwaitTransactionAcceptance(client *avalanchegoclient.Clie
 		}
 		logrus.Tracef("Status for transaction %s: %s", txID, status)
 		if status == choices.Accepted {
+			client.RecordTx(avalanchegoclient.XChain, txID.String(), avalanchegoclient.TxAccepted)
 			return nil
 		}
 		if status == choices.Rejected {
+			client.RecordTx(avalanchegoclient.XChain, txID.String(), avalanchegoclient.TxRejected)
 			return stacktrace.NewError("Transaction %s was %s", txID, status)
 		}
 	}
+	client.RecordTx(avalanchegoclient.XChain, txID.String(), avalanchegoclient.TxTimedOut)
 	return stacktrace.NewError("Timed out waiting for transaction %s to be accepted on the XChain.", txID)
 }
 
+// AwaitTransactionStall checks the [txID] is not decided for [duration], e.g. while the network lost its quorum
+func (x *XChainHelper) AwaitTransactionStall(client *avalanchegoclient.Client, txID ids.ID, duration time.Duration) error {
+
+	for startTime := time.Now(); time.Since(startTime) < duration; time.Sleep(time.Second) {
+		status, err := client.XChainAPI().GetTxStatus(txID)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get status.")
+		}
+		logrus.Tracef("Status for transaction %s: %s", txID, status)
+		if status.Decided() {
+			return stacktrace.NewError("Transaction %s was %s on the XChain, expected it to stall", txID, status)
+		}
+	}
+	return nil
+}
+
+// ConflictSetDecisions is the status of each transaction of a conflict set on each node
+type ConflictSetDecisions struct {
+	TxIDs []ids.ID
+	// Nodes are sorted, Statuses holds the status of each of the TxIDs, in order, keyed by node
+	Nodes    []string
+	Statuses map[string][]choices.Status
+}
+
+// Accepted returns the index of the transaction accepted on [node], -1 if none is
+func (d *ConflictSetDecisions) Accepted(node string) int {
+	for i, status := range d.Statuses[node] {
+		if status == choices.Accepted {
+			return i
+		}
+	}
+	return -1
+}
+
+// String formats the decisions as a table, one row per node and one column per transaction
+func (d *ConflictSetDecisions) String() string {
+	header := fmt.Sprintf("%-20s", "node")
+	for _, txID := range d.TxIDs {
+		header += fmt.Sprintf(" %-52s", txID)
+	}
+	rows := []string{strings.TrimRight(header, " ")}
+	for _, node := range d.Nodes {
+		row := fmt.Sprintf("%-20s", node)
+		for _, status := range d.Statuses[node] {
+			row += fmt.Sprintf(" %-52s", status)
+		}
+		rows = append(rows, strings.TrimRight(row, " "))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// AwaitConflictSetDecision waits for the conflicting [txIDs] to be decided on each node of [clients], keyed by node,
+// within [timeout] and returns the ID of the accepted transaction
+// Exactly one transaction must be accepted, the same on every node, the others are rejected. A node that never
+// learned of a rejected transaction reports it as Unknown, which counts as rejected
+// The decisions of the nodes are returned even on failure, and their table is part of the error
+// The outcome of the transactions is recorded through the clients
+func (x *XChainHelper) AwaitConflictSetDecision(clients map[string]*avalanchegoclient.Client, txIDs []ids.ID, timeout time.Duration) (ids.ID, *ConflictSetDecisions, error) {
+	if len(txIDs) == 0 || len(clients) == 0 {
+		return ids.Empty, nil, stacktrace.NewError("Awaiting a conflict set needs transactions and nodes, got %d transactions and %d nodes",
+			len(txIDs), len(clients))
+	}
+	decisions := &ConflictSetDecisions{
+		TxIDs:    txIDs,
+		Statuses: map[string][]choices.Status{},
+	}
+	for node := range clients {
+		decisions.Nodes = append(decisions.Nodes, node)
+	}
+	sort.Strings(decisions.Nodes)
+
+	for startTime := time.Now(); ; time.Sleep(time.Second) {
+		for _, node := range decisions.Nodes {
+			statuses := make([]choices.Status, len(txIDs))
+			for i, txID := range txIDs {
+				status, err := clients[node].XChainAPI().GetTxStatus(txID)
+				if err != nil {
+					logrus.Debugf("Failed to get the status of transaction %s from node %s: %v", txID, node, err)
+				}
+				statuses[i] = status
+			}
+			decisions.Statuses[node] = statuses
+		}
+
+		accepted, decided, err := decisions.outcome()
+		if err != nil {
+			return ids.Empty, decisions, stacktrace.Propagate(err, "Inconsistent conflict set decisions:\n%s", decisions)
+		}
+		if decided {
+			recordConflictSet(clients[decisions.Nodes[0]], txIDs, accepted)
+			logrus.Infof("Transaction %s was accepted by the %d nodes, its %d conflicting transactions were rejected.",
+				txIDs[accepted], len(decisions.Nodes), len(txIDs)-1)
+			return txIDs[accepted], decisions, nil
+		}
+		if time.Since(startTime) >= timeout {
+			recordConflictSet(clients[decisions.Nodes[0]], txIDs, -1)
+			return ids.Empty, decisions, stacktrace.NewError("Timed out waiting for the conflict set to be decided on the XChain:\n%s", decisions)
+		}
+	}
+}
+
+// recordConflictSet records the [accepted] transaction of [txIDs] through [client], and the others as rejected
+// if no transaction was accepted, -1, they're recorded as timed out
+func recordConflictSet(client *avalanchegoclient.Client, txIDs []ids.ID, accepted int) {
+	for i, txID := range txIDs {
+		switch {
+		case accepted == -1:
+			client.RecordTx(avalanchegoclient.XChain, txID.String(), avalanchegoclient.TxTimedOut)
+		case i == accepted:
+			client.RecordTx(avalanchegoclient.XChain, txID.String(), avalanchegoclient.TxAccepted)
+		default:
+			client.RecordTx(avalanchegoclient.XChain, txID.String(), avalanchegoclient.TxRejected)
+		}
+	}
+}
+
+// outcome returns the index of the accepted transaction and whether every node decided every transaction
+// it fails as soon as two transactions are accepted, which no later poll can fix
+func (d *ConflictSetDecisions) outcome() (int, bool, error) {
+	accepted, decided := -1, true
+	for _, node := range d.Nodes {
+		for i, status := range d.Statuses[node] {
+			switch status {
+			case choices.Accepted:
+				if accepted != -1 && accepted != i {
+					return -1, false, stacktrace.NewError("Conflicting transactions %s and %s were both accepted",
+						d.TxIDs[accepted], d.TxIDs[i])
+				}
+				accepted = i
+			case choices.Processing:
+				decided = false
+			}
+		}
+	}
+	if accepted == -1 {
+		return -1, false, nil
+	}
+	for _, node := range d.Nodes {
+		if d.Statuses[node][accepted] != choices.Accepted {
+			return accepted, false, nil
+		}
+	}
+	return accepted, decided, nil
+}
+
 // CheckBalance validates the [address] balance is equal to [amount]
 func (x *XChainHelper) CheckBalance(client *avalanchegoclient.Client, address string, assetID string, expectedAmount uint64) error {
 	xBalance, err := client.XChainAPI().GetBalance(address, assetID, false)
@@ -50,6 +202,38 @@ func (x *XChainHelper) CheckBalance(client *avalanchegoclient.Client, address st
 	return nil
 }
 
+// CheckBalances validates the [address] balance of each asset of [expectedAmounts], keyed by asset ID or alias (e.g. AVAX)
+func (x *XChainHelper) CheckBalances(client *avalanchegoclient.Client, address string, expectedAmounts map[string]uint64) error {
+	reply, err := client.XChainAPI().GetAllBalances(address, false)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to retrieve X Chain balances.")
+	}
+
+	balances := map[string]uint64{}
+	for _, balance := range reply.Balances {
+		balances[balance.AssetID] = uint64(balance.Balance)
+	}
+	for assetID, expectedAmount := range expectedAmounts {
+		if balances[assetID] != expectedAmount {
+			return stacktrace.NewError("Found unexpected X Chain Balance of asset %s for address: %s. Expected: %v, found: %v",
+				assetID, address, expectedAmount, balances[assetID])
+		}
+	}
+
+	return nil
+}
+
+// AwaitBalances waits for the [address] balances to match [expectedAmounts], see CheckBalances, within [timeout]
+// e.g. while [client] hasn't accepted the last transaction issued through another node
+func (x *XChainHelper) AwaitBalances(client *avalanchegoclient.Client, address string, expectedAmounts map[string]uint64, timeout time.Duration) error {
+	for startTime := time.Now(); ; time.Sleep(time.Second) {
+		err := x.CheckBalances(client, address, expectedAmounts)
+		if err == nil || time.Since(startTime) >= timeout {
+			return err
+		}
+	}
+}
+
 // XChain is a helper to chain request to the correct VM
 func XChain() *XChainHelper {
 	return &XChainHelper{}
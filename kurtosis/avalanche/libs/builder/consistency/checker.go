@@ -0,0 +1,218 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package consistency
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+// Checker compares the state of the P, X and C Chains across nodes and reports where they diverge:
+//   - the P Chain height and the last accepted C Chain block
+//   - the P Chain current and pending validators of the primary network and of the added subnets
+//   - the X Chain balances of the added addresses
+//   - the C Chain block hashes at the added heights
+//
+// avalanchego doesn't expose the last accepted vertex of the X Chain, its consistency is checked through the balances
+type Checker struct {
+	clients    map[string]*avalanchegoclient.Client
+	nodes      []string
+	subnetIDs  []ids.ID
+	xAddresses []string
+	cHeights   []uint64
+}
+
+// New creates a Checker comparing the nodes of [clients], keyed by node ID
+func New(clients map[string]*avalanchegoclient.Client) *Checker {
+	c := &Checker{
+		clients:   clients,
+		subnetIDs: []ids.ID{ids.Empty},
+	}
+	for node := range clients {
+		c.nodes = append(c.nodes, node)
+	}
+	sort.Strings(c.nodes)
+	return c
+}
+
+// NewFromTopology creates a Checker comparing the Genesis and the Nodes of [t]
+func NewFromTopology(t *topology.Topology) *Checker {
+	return New(t.GetClients())
+}
+
+// NewFromNetwork creates a Checker comparing every running node of [network]
+func NewFromNetwork(network networks.Network) *Checker {
+	return New(networksavalanche.Cast(network).GetNodeClients())
+}
+
+// Subnets adds the subnets whose validators are compared, on top of the primary network
+func (c *Checker) Subnets(subnetIDs ...ids.ID) *Checker {
+	c.subnetIDs = append(c.subnetIDs, subnetIDs...)
+	return c
+}
+
+// XChainAddresses adds the X Chain addresses whose balances are compared
+func (c *Checker) XChainAddresses(addresses ...string) *Checker {
+	c.xAddresses = append(c.xAddresses, addresses...)
+	return c
+}
+
+// CChainHeights adds the C Chain heights whose block hashes are compared
+func (c *Checker) CChainHeights(heights ...uint64) *Checker {
+	c.cHeights = append(c.cHeights, heights...)
+	return c
+}
+
+// Check queries every node once and returns the Report of the divergences
+// A node that fails to answer a query diverges on it, even if every node fails the same way
+func (c *Checker) Check() *Report {
+	report := &Report{Nodes: c.nodes}
+	c.check(report, "P Chain height", pChainHeight)
+	c.check(report, "last accepted C Chain block", cChainLastAccepted)
+	for _, subnetID := range c.subnetIDs {
+		subnetID := subnetID
+		c.check(report, fmt.Sprintf("P Chain current validators of subnet %s", subnetID), func(client *avalanchegoclient.Client) (string, error) {
+			return pChainCurrentValidators(client, subnetID)
+		})
+		c.check(report, fmt.Sprintf("P Chain pending validators of subnet %s", subnetID), func(client *avalanchegoclient.Client) (string, error) {
+			return pChainPendingValidators(client, subnetID)
+		})
+	}
+	for _, address := range c.xAddresses {
+		address := address
+		c.check(report, fmt.Sprintf("X Chain balances of %s", address), func(client *avalanchegoclient.Client) (string, error) {
+			return xChainBalances(client, address)
+		})
+	}
+	for _, height := range c.cHeights {
+		height := height
+		c.check(report, fmt.Sprintf("C Chain block hash at height %d", height), func(client *avalanchegoclient.Client) (string, error) {
+			return cChainBlockHash(client, height)
+		})
+	}
+	return report
+}
+
+// AwaitConsistency checks the nodes until they agree, as they may still be processing the last decisions,
+// and fails with the divergences of the last check if they still disagree after [timeout]
+func (c *Checker) AwaitConsistency(timeout time.Duration) error {
+	if len(c.nodes) == 0 {
+		return stacktrace.NewError("No node to check the consistency of")
+	}
+	for startTime := time.Now(); ; time.Sleep(time.Second) {
+		report := c.Check()
+		if report.Consistent() {
+			logrus.Infof("The chains of the %d nodes are consistent.", len(c.nodes))
+			return nil
+		}
+		if time.Since(startTime) >= timeout {
+			return report.Err()
+		}
+		logrus.Debugf("Nodes still diverge:\n%s", report)
+	}
+}
+
+// check runs [get] on every node and adds the divergence on [name], if any, to [report]
+func (c *Checker) check(report *Report, name string, get func(client *avalanchegoclient.Client) (string, error)) {
+	values, errs := c.query(get)
+	report.compare(name, values, errs)
+}
+
+// query runs [get] on every node and returns the value of each node, and the error of the nodes that failed
+func (c *Checker) query(get func(client *avalanchegoclient.Client) (string, error)) (map[string]string, map[string]error) {
+	values := make(map[string]string, len(c.nodes))
+	errs := map[string]error{}
+	for _, node := range c.nodes {
+		value, err := get(c.clients[node])
+		if err != nil {
+			errs[node] = err
+			continue
+		}
+		values[node] = value
+	}
+	return values, errs
+}
+
+func pChainHeight(client *avalanchegoclient.Client) (string, error) {
+	height, err := client.PChainAPI().GetHeight()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(height), nil
+}
+
+func cChainLastAccepted(client *avalanchegoclient.Client) (string, error) {
+	header, err := client.CChaiConcurrentEth().HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d %s", header.Number, header.Hash().Hex()), nil
+}
+
+func cChainBlockHash(client *avalanchegoclient.Client, height uint64) (string, error) {
+	header, err := client.CChaiConcurrentEth().HeaderByNumber(context.Background(), new(big.Int).SetUint64(height))
+	if err != nil {
+		return "", err
+	}
+	return header.Hash().Hex(), nil
+}
+
+func pChainCurrentValidators(client *avalanchegoclient.Client, subnetID ids.ID) (string, error) {
+	validators, err := client.PChainAPI().GetCurrentValidators(subnetID)
+	if err != nil {
+		return "", err
+	}
+	return stakers(validators), nil
+}
+
+func pChainPendingValidators(client *avalanchegoclient.Client, subnetID ids.ID) (string, error) {
+	validators, delegators, err := client.PChainAPI().GetPendingValidators(subnetID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("validators: %s delegators: %s", stakers(validators), stakers(delegators)), nil
+}
+
+// stakers formats the staking periods of the validators or delegators returned by the P Chain API, sorted
+// the uptime and connection of the validators are left out, they're seen differently by each node
+func stakers(apiStakers []interface{}) string {
+	formatted := make([]string, 0, len(apiStakers))
+	for _, apiStaker := range apiStakers {
+		staker, ok := apiStaker.(map[string]interface{})
+		if !ok {
+			formatted = append(formatted, fmt.Sprint(apiStaker))
+			continue
+		}
+		delegators, _ := staker["delegators"].([]interface{})
+		formatted = append(formatted, fmt.Sprintf("%v[%v-%v stake:%v weight:%v delegators:%s]",
+			staker["nodeID"], staker["startTime"], staker["endTime"], staker["stakeAmount"], staker["weight"], stakers(delegators)))
+	}
+	sort.Strings(formatted)
+	return "{" + strings.Join(formatted, " ") + "}"
+}
+
+func xChainBalances(client *avalanchegoclient.Client, address string) (string, error) {
+	reply, err := client.XChainAPI().GetAllBalances(address, false)
+	if err != nil {
+		return "", err
+	}
+	balances := make([]string, 0, len(reply.Balances))
+	for _, balance := range reply.Balances {
+		balances = append(balances, fmt.Sprintf("%s:%d", balance.AssetID, balance.Balance))
+	}
+	sort.Strings(balances)
+	return strings.Join(balances, " "), nil
+}
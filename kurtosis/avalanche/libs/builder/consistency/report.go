@@ -0,0 +1,68 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package consistency
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/palantir/stacktrace"
+)
+
+// Report holds the divergences found by a Checker between its Nodes
+type Report struct {
+	Nodes       []string
+	Divergences []*Divergence
+}
+
+// Divergence is a state the nodes don't agree on, with the Values seen by each node
+// and the Errors of the nodes that failed to answer
+type Divergence struct {
+	Check  string
+	Values map[string]string
+	Errors map[string]error
+}
+
+// Consistent returns true if the nodes agree on every state
+func (r *Report) Consistent() bool {
+	return len(r.Divergences) == 0
+}
+
+// Err returns an error listing the divergences, nil if the nodes are consistent
+func (r *Report) Err() error {
+	if r.Consistent() {
+		return nil
+	}
+	return stacktrace.NewError("The nodes diverge on %d check(s):\n%s", len(r.Divergences), r)
+}
+
+// String formats the divergences, with the value seen by each node
+func (r *Report) String() string {
+	lines := make([]string, 0, len(r.Divergences)*(len(r.Nodes)+1))
+	for _, divergence := range r.Divergences {
+		lines = append(lines, divergence.Check+":")
+		for _, node := range r.Nodes {
+			value := divergence.Values[node]
+			if err, failed := divergence.Errors[node]; failed {
+				value = fmt.Sprintf("error: %v", err)
+			}
+			lines = append(lines, fmt.Sprintf("  %-20s %s", node, value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// compare records a Divergence on [check] if a node failed to answer with [errs], or the nodes don't all have
+// the same value
+func (r *Report) compare(check string, values map[string]string, errs map[string]error) {
+	diverges := len(errs) > 0
+	for _, node := range r.Nodes {
+		if values[node] != values[r.Nodes[0]] {
+			diverges = true
+		}
+	}
+	if diverges {
+		r.Divergences = append(r.Divergences, &Divergence{Check: check, Values: values, Errors: errs})
+	}
+}
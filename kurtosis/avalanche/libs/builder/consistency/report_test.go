@@ -0,0 +1,62 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package consistency
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReportCompare(t *testing.T) {
+	nodes := []string{"node-1", "node-2", "node-3"}
+	errUnreachable := errors.New("unreachable")
+
+	tests := []struct {
+		name     string
+		values   map[string]string
+		errs     map[string]error
+		diverges bool
+	}{
+		{
+			name:   "same values",
+			values: map[string]string{"node-1": "10", "node-2": "10", "node-3": "10"},
+		},
+		{
+			name:     "different values",
+			values:   map[string]string{"node-1": "10", "node-2": "11", "node-3": "10"},
+			diverges: true,
+		},
+		{
+			name:     "one node failed",
+			values:   map[string]string{"node-1": "10", "node-2": "10"},
+			errs:     map[string]error{"node-3": errUnreachable},
+			diverges: true,
+		},
+		{
+			name:     "every node failed the same way",
+			values:   map[string]string{},
+			errs:     map[string]error{"node-1": errUnreachable, "node-2": errUnreachable, "node-3": errUnreachable},
+			diverges: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := &Report{Nodes: nodes}
+			report.compare("check", test.values, test.errs)
+			if report.Consistent() == test.diverges {
+				t.Fatalf("expected the nodes to diverge: %v, got the report:\n%s", test.diverges, report)
+			}
+			if test.diverges && report.Err() == nil {
+				t.Fatal("expected an error listing the divergence")
+			}
+			for node := range test.errs {
+				if !strings.Contains(report.String(), node) || !strings.Contains(report.String(), "error: unreachable") {
+					t.Fatalf("expected the report to show the error of %s, got:\n%s", node, report)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/txhelper"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/coreth"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+// CChainLoadConfig configures a C Chain load run
+type CChainLoadConfig struct {
+	// Accounts is the number of funded accounts, each one sends TxsPerAccount copies of Call with consecutive nonces
+	Accounts      int
+	TxsPerAccount int
+	// Amount funds each account, in nAVAX, it must pay the gas and the value of each of its transactions
+	Amount uint64
+	// Call is the transaction sent by the accounts, a transfer or a contract call
+	Call txhelper.EthCall
+	// GasPrice of the transactions, the one suggested by the node if nil
+	GasPrice *big.Int
+	// TargetTPS is the issuance rate over all the nodes, 0 issues as fast as possible
+	TargetTPS float64
+	// Timeout is how long each transaction has to get a receipt after being issued
+	Timeout time.Duration
+	// PollInterval is how often the receipts are polled, it bounds the latency precision
+	PollInterval time.Duration
+}
+
+// EthReport is the Report of a C Chain load run, with the gas used and the block production
+type EthReport struct {
+	*Report
+	// GasUsed by the transactions that got a receipt, TxBlocks is the number of blocks that included them
+	// and LastTxBlock the highest of them
+	GasUsed     uint64
+	TxBlocks    int
+	LastTxBlock uint64
+	// Blocks were produced during the run, at BlocksPerSecond
+	Blocks          uint64
+	BlocksPerSecond float64
+}
+
+// Log logs the EthReport
+func (r *EthReport) Log() {
+	r.Report.Log()
+	logrus.Infof("%s load: %d gas used, %d blocks including the transactions, %d blocks produced at %.2f blocks/s",
+		r.Name, r.GasUsed, r.TxBlocks, r.Blocks, r.BlocksPerSecond)
+}
+
+// CChainLoadGenerator issues pre-signed EIP-155 transactions across the nodes of a network at a target rate
+// and reports the receipt latency, the gas used and the block production rate
+type CChainLoadGenerator struct {
+	clients []*avalanchegoclient.Client
+	config  CChainLoadConfig
+	// accounts are the pre-signed transactions of each account, issued to the same node
+	accounts []*ethAccount
+
+	lock     sync.Mutex
+	gasUsed  uint64
+	txBlocks map[uint64]bool
+}
+
+type ethAccount struct {
+	address common.Address
+	client  *avalanchegoclient.Client
+	txs     []*types.Transaction
+}
+
+// NewCChainLoadGenerator creates a load generator issuing to the C Chain of the [clients], one per node
+func NewCChainLoadGenerator(clients []*avalanchegoclient.Client, config CChainLoadConfig) (*CChainLoadGenerator, error) {
+	if len(clients) == 0 {
+		return nil, stacktrace.NewError("The load generator needs at least one client")
+	}
+	if config.Accounts <= 0 || config.TxsPerAccount <= 0 {
+		return nil, stacktrace.NewError("The load generator needs accounts and transactions, got %d accounts of %d transactions",
+			config.Accounts, config.TxsPerAccount)
+	}
+	if config.Timeout == 0 {
+		config.Timeout = constants.TimeoutDuration
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	return &CChainLoadGenerator{
+		clients:  clients,
+		config:   config,
+		txBlocks: map[uint64]bool{},
+	}, nil
+}
+
+// Prepare funds the accounts from [genesis] and pre-signs their transactions
+// the accounts are spread over the nodes, the transactions of each one are issued to a single node
+func (g *CChainLoadGenerator) Prepare(genesis *topology.Genesis) error {
+	factory := crypto.FactorySECP256K1R{}
+	keys := make([]*crypto.PrivateKeySECP256K1R, 0, g.config.Accounts)
+	addresses := make([]common.Address, 0, g.config.Accounts)
+	for i := 0; i < g.config.Accounts; i++ {
+		key, err := factory.NewPrivateKey()
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to generate an account key")
+		}
+		keys = append(keys, key.(*crypto.PrivateKeySECP256K1R))
+		addresses = append(addresses, evm.GetEthAddress(key.(*crypto.PrivateKeySECP256K1R)))
+	}
+
+	if err := genesis.TryFundCChainAddresses(addresses, g.config.Amount); err != nil {
+		return stacktrace.Propagate(err, "Failed to fund the accounts")
+	}
+
+	ctx := context.Background()
+	ethClient := g.clients[0].CChaiConcurrentEth()
+	chainID, err := ethClient.ChainID(ctx)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to get the C Chain ID")
+	}
+	gasPrice := g.config.GasPrice
+	if gasPrice == nil {
+		if gasPrice, err = ethClient.SuggestGasPrice(ctx); err != nil {
+			return stacktrace.Propagate(err, "Failed to get the gas price")
+		}
+	}
+
+	g.accounts = make([]*ethAccount, 0, len(keys))
+	for i, key := range keys {
+		// the accounts are new, their first nonce is 0
+		txs, err := txhelper.CreateConsecutiveEthTxs(chainID, 0, g.config.TxsPerAccount, g.config.Call, gasPrice, key)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to sign the transactions of account %s", addresses[i].Hex())
+		}
+		g.accounts = append(g.accounts, &ethAccount{
+			address: addresses[i],
+			client:  g.clients[i%len(g.clients)],
+			txs:     txs,
+		})
+	}
+
+	// the funding was only awaited on the genesis node, the transactions of an account issued
+	// before its node sees the funds would be refused and the account marked broken
+	for _, account := range g.accounts {
+		if err := g.awaitFunding(account); err != nil {
+			return err
+		}
+	}
+	logrus.Infof("Prepared %d accounts of %d C Chain transactions.", len(g.accounts), g.config.TxsPerAccount)
+	return nil
+}
+
+// Run issues the prepared transactions at the target rate, the nth transaction of every account before the next ones,
+// waits for their receipts and returns the EthReport of the run
+// An account stops being issued after one of its transactions is refused, the next nonces would never be mined
+func (g *CChainLoadGenerator) Run() (*EthReport, error) {
+	if len(g.accounts) == 0 {
+		return nil, stacktrace.NewError("The load generator was not prepared")
+	}
+
+	ctx := context.Background()
+	startBlock, err := g.clients[0].CChaiConcurrentEth().BlockNumber(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to get the C Chain height")
+	}
+	startTime := time.Now()
+
+	issue := func(sender, txIndex int) error {
+		tx := g.accounts[sender].txs[txIndex]
+		if err := g.accounts[sender].client.CChaiConcurrentEth().SendTransaction(ctx, tx); err != nil {
+			return fmt.Errorf("transaction %s: %w", tx.Hash().Hex(), err)
+		}
+		return nil
+	}
+	await := func(sender, txIndex int, issuedAt time.Time, rec *recorder) {
+		account := g.accounts[sender]
+		g.awaitReceipt(account.client, account.txs[txIndex].Hash(), issuedAt, rec)
+	}
+	rec := runLoad(len(g.accounts), g.config.TxsPerAccount, g.config.TargetTPS, issue, await)
+
+	endBlock, err := g.clients[0].CChaiConcurrentEth().BlockNumber(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to get the C Chain height")
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	report := &EthReport{
+		Report:   rec.report("C Chain"),
+		GasUsed:  g.gasUsed,
+		TxBlocks: len(g.txBlocks),
+		Blocks:   endBlock - startBlock,
+	}
+	for block := range g.txBlocks {
+		if block > report.LastTxBlock {
+			report.LastTxBlock = block
+		}
+	}
+	if elapsed := time.Since(startTime); elapsed > 0 {
+		report.BlocksPerSecond = float64(report.Blocks) / elapsed.Seconds()
+	}
+	return report, nil
+}
+
+// awaitFunding waits for the node of [account] to see its funds
+func (g *CChainLoadGenerator) awaitFunding(account *ethAccount) error {
+	for startTime := time.Now(); ; time.Sleep(g.config.PollInterval) {
+		balance, err := account.client.CChaiConcurrentEth().BalanceAt(context.Background(), account.address, nil)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get the balance of account %s", account.address.Hex())
+		}
+		if balance.Sign() > 0 {
+			return nil
+		}
+		if time.Since(startTime) > g.config.Timeout {
+			return stacktrace.NewError("Timed out waiting for the funding of account %s", account.address.Hex())
+		}
+	}
+}
+
+// awaitReceipt polls the receipt of [txHash], issued at [issuedAt], until it's mined or times out
+// a transaction that was mined but failed to execute counts as rejected
+func (g *CChainLoadGenerator) awaitReceipt(client *avalanchegoclient.Client, txHash common.Hash, issuedAt time.Time, rec *recorder) {
+	for {
+		receipt, err := client.CChaiConcurrentEth().TransactionReceipt(context.Background(), txHash)
+		switch {
+		case err == nil:
+			g.recordReceipt(receipt)
+			if receipt.Status == types.ReceiptStatusSuccessful {
+				rec.accepted(issuedAt, time.Now())
+			} else {
+				rec.rejectedTx()
+			}
+			return
+		case err != coreth.NotFound:
+			logrus.Debugf("Failed to get the receipt of transaction %s: %v", txHash.Hex(), err)
+		}
+		if time.Since(issuedAt) > g.config.Timeout {
+			rec.timedOutTx()
+			return
+		}
+		time.Sleep(g.config.PollInterval)
+	}
+}
+
+func (g *CChainLoadGenerator) recordReceipt(receipt *types.Receipt) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.gasUsed += receipt.GasUsed
+	if receipt.BlockNumber != nil {
+		g.txBlocks[receipt.BlockNumber.Uint64()] = true
+	}
+}
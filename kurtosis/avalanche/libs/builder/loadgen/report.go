@@ -0,0 +1,143 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package loadgen
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/sirupsen/logrus"
+)
+
+// Report summarizes a load run: how many transactions were issued and decided, the accepted TPS
+// and the finality latency percentiles, measured from issuance to the first poll that saw the acceptance
+type Report struct {
+	Name string
+	// Issued transactions were accepted by the API of a node, IssueFailures were refused by it
+	Issued        int
+	IssueFailures int
+	Accepted      int
+	Rejected      int
+	// TimedOut transactions were still undecided at the end of the run
+	TimedOut int
+	// Duration runs from the first issuance to the last acceptance
+	Duration time.Duration
+	TPS      float64
+	P50      time.Duration
+	P95      time.Duration
+	P99      time.Duration
+}
+
+// Succeeded returns true if every issued transaction was accepted
+func (r *Report) Succeeded() bool {
+	return r.IssueFailures == 0 && r.Rejected == 0 && r.TimedOut == 0 && r.Accepted == r.Issued
+}
+
+// Log logs the Report
+func (r *Report) Log() {
+	logrus.Infof("%s load: issued %d, issue failures %d, accepted %d, rejected %d, timed out %d",
+		r.Name, r.Issued, r.IssueFailures, r.Accepted, r.Rejected, r.TimedOut)
+	logrus.Infof("%s load: %.2f TPS over %v, finality p50 %v p95 %v p99 %v",
+		r.Name, r.TPS, r.Duration, r.P50, r.P95, r.P99)
+}
+
+// TxsReport returns the transaction counts of the Report, to be added to a TestReport
+func (r *Report) TxsReport() networksavalanche.TxsReport {
+	return networksavalanche.TxsReport{
+		Issued:        r.Issued,
+		IssueFailures: r.IssueFailures,
+		Accepted:      r.Accepted,
+		Rejected:      r.Rejected,
+		TimedOut:      r.TimedOut,
+	}
+}
+
+// recorder collects the outcome of the transactions of a load run, it's safe for concurrent use
+type recorder struct {
+	lock          sync.Mutex
+	start         time.Time
+	lastAccepted  time.Time
+	issued        int
+	issueFailures int
+	rejected      int
+	timedOut      int
+	latencies     []time.Duration
+}
+
+func (r *recorder) issuedTx(issuedAt time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.start.IsZero() || issuedAt.Before(r.start) {
+		r.start = issuedAt
+	}
+	r.issued++
+}
+
+func (r *recorder) issueFailed() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.issueFailures++
+}
+
+func (r *recorder) accepted(issuedAt time.Time, acceptedAt time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if acceptedAt.After(r.lastAccepted) {
+		r.lastAccepted = acceptedAt
+	}
+	r.latencies = append(r.latencies, acceptedAt.Sub(issuedAt))
+}
+
+func (r *recorder) rejectedTx() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.rejected++
+}
+
+func (r *recorder) timedOutTx() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.timedOut++
+}
+
+func (r *recorder) report(name string) *Report {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	report := &Report{
+		Name:          name,
+		Issued:        r.issued,
+		IssueFailures: r.issueFailures,
+		Accepted:      len(r.latencies),
+		Rejected:      r.rejected,
+		TimedOut:      r.timedOut,
+	}
+	if len(r.latencies) == 0 {
+		return report
+	}
+
+	report.Duration = r.lastAccepted.Sub(r.start)
+	if report.Duration > 0 {
+		report.TPS = float64(report.Accepted) / report.Duration.Seconds()
+	}
+
+	latencies := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.P50 = percentile(latencies, 0.50)
+	report.P95 = percentile(latencies, 0.95)
+	report.P99 = percentile(latencies, 0.99)
+	return report
+}
+
+// percentile returns the [p] percentile of the sorted [latencies], using the nearest rank
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(latencies)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return latencies[rank]
+}
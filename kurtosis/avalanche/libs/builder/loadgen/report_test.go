@@ -0,0 +1,167 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package loadgen
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	hundred := make([]time.Duration, 0, 100)
+	for i := 1; i <= 100; i++ {
+		hundred = append(hundred, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		p         float64
+		expected  time.Duration
+	}{
+		{name: "p50 of 100", latencies: hundred, p: 0.50, expected: 50 * time.Millisecond},
+		{name: "p95 of 100", latencies: hundred, p: 0.95, expected: 95 * time.Millisecond},
+		{name: "p99 of 100", latencies: hundred, p: 0.99, expected: 99 * time.Millisecond},
+		{name: "p100 of 100", latencies: hundred, p: 1, expected: 100 * time.Millisecond},
+		{name: "p0 is the minimum", latencies: hundred, p: 0, expected: time.Millisecond},
+		{name: "single latency", latencies: []time.Duration{time.Second}, p: 0.99, expected: time.Second},
+		{name: "p50 rounds up the rank", latencies: []time.Duration{1, 2, 3}, p: 0.50, expected: 2},
+		{name: "p99 of few is the maximum", latencies: []time.Duration{1, 2, 3, 4}, p: 0.99, expected: 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := percentile(test.latencies, test.p); actual != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRecorderReport(t *testing.T) {
+	start := time.Now()
+	rec := &recorder{}
+
+	// issued out of order, the run starts at the first issuance
+	rec.issuedTx(start.Add(time.Second))
+	rec.issuedTx(start)
+	rec.issuedTx(start.Add(2 * time.Second))
+	rec.issuedTx(start.Add(3 * time.Second))
+	rec.issueFailed()
+
+	rec.accepted(start.Add(2*time.Second), start.Add(5*time.Second))
+	rec.accepted(start, start.Add(time.Second))
+	rec.accepted(start.Add(time.Second), start.Add(3*time.Second))
+	rec.rejectedTx()
+
+	report := rec.report("test")
+	if report.Name != "test" || report.Issued != 4 || report.IssueFailures != 1 ||
+		report.Accepted != 3 || report.Rejected != 1 || report.TimedOut != 0 {
+		t.Fatalf("unexpected counts %+v", report)
+	}
+	if report.Duration != 5*time.Second {
+		t.Fatalf("expected the run to last from the first issuance to the last acceptance, got %v", report.Duration)
+	}
+	if report.TPS != 3.0/5 {
+		t.Fatalf("expected 0.6 TPS, got %v", report.TPS)
+	}
+	if report.P50 != 2*time.Second || report.P95 != 3*time.Second || report.P99 != 3*time.Second {
+		t.Fatalf("unexpected percentiles p50 %v p95 %v p99 %v", report.P50, report.P95, report.P99)
+	}
+	if report.Succeeded() {
+		t.Fatal("expected a report with failures not to succeed")
+	}
+}
+
+func TestRecorderReportSucceeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   func(rec *recorder, issuedAt time.Time)
+		expected bool
+	}{
+		{
+			name: "all accepted",
+			record: func(rec *recorder, issuedAt time.Time) {
+				rec.issuedTx(issuedAt)
+				rec.accepted(issuedAt, issuedAt.Add(time.Second))
+			},
+			expected: true,
+		},
+		{
+			name: "issue failure",
+			record: func(rec *recorder, issuedAt time.Time) {
+				rec.issueFailed()
+			},
+		},
+		{
+			name: "rejected",
+			record: func(rec *recorder, issuedAt time.Time) {
+				rec.issuedTx(issuedAt)
+				rec.rejectedTx()
+			},
+		},
+		{
+			name: "timed out",
+			record: func(rec *recorder, issuedAt time.Time) {
+				rec.issuedTx(issuedAt)
+				rec.timedOutTx()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := &recorder{}
+			test.record(rec, time.Now())
+			if succeeded := rec.report("test").Succeeded(); succeeded != test.expected {
+				t.Fatalf("expected Succeeded to be %v", test.expected)
+			}
+		})
+	}
+}
+
+func TestRecorderEmptyReport(t *testing.T) {
+	rec := &recorder{}
+	rec.issuedTx(time.Now())
+	rec.timedOutTx()
+
+	report := rec.report("test")
+	if report.Accepted != 0 || report.TimedOut != 1 {
+		t.Fatalf("unexpected counts %+v", report)
+	}
+	if report.Duration != 0 || report.TPS != 0 || report.P50 != 0 || report.P99 != 0 {
+		t.Fatalf("expected no timing without accepted transactions, got %+v", report)
+	}
+}
+
+func TestRecorderConcurrentUse(t *testing.T) {
+	const workers, txsPerWorker = 10, 100
+
+	start := time.Now()
+	rec := &recorder{}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < txsPerWorker; j++ {
+				rec.issuedTx(start)
+				rec.accepted(start, start.Add(time.Duration(j+1)*time.Millisecond))
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := rec.report("test")
+	if report.Issued != workers*txsPerWorker || report.Accepted != workers*txsPerWorker {
+		t.Fatalf("expected %d issued and accepted transactions, got %+v", workers*txsPerWorker, report)
+	}
+	if report.Duration != txsPerWorker*time.Millisecond {
+		t.Fatalf("expected the run to last %v, got %v", txsPerWorker*time.Millisecond, report.Duration)
+	}
+	if report.P50 != 50*time.Millisecond || report.P99 != 99*time.Millisecond {
+		t.Fatalf("unexpected percentiles p50 %v p99 %v", report.P50, report.P99)
+	}
+}
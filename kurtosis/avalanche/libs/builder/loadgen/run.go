@@ -0,0 +1,76 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package loadgen
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// issueFunc issues the [txIndex] transaction of [sender], an error means the node refused it
+type issueFunc func(sender, txIndex int) error
+
+// awaitFunc waits for the [txIndex] transaction of [sender], issued at [issuedAt], to be decided and records it in [rec]
+type awaitFunc func(sender, txIndex int, issuedAt time.Time, rec *recorder)
+
+// runLoad issues [txsPerSender] transactions of each of the [senders] at [targetTPS] (0 issues as fast as possible),
+// the nth transaction of every sender before the next ones, and returns the recorder of their outcome
+// The issued transactions of a sender are awaited in order by a goroutine of its own
+// A sender stops being issued after one of its transactions is refused, the next ones depend on it
+func runLoad(senders, txsPerSender int, targetTPS float64, issue issueFunc, await awaitFunc) *recorder {
+	type issuedTx struct {
+		txIndex  int
+		issuedAt time.Time
+	}
+
+	rec := &recorder{}
+	issued := make([]chan issuedTx, senders)
+	var wg sync.WaitGroup
+	for sender := range issued {
+		issued[sender] = make(chan issuedTx, txsPerSender)
+		wg.Add(1)
+		go func(sender int, txs <-chan issuedTx) {
+			defer wg.Done()
+			for tx := range txs {
+				await(sender, tx.txIndex, tx.issuedAt, rec)
+			}
+		}(sender, issued[sender])
+	}
+
+	var ticker *time.Ticker
+	if targetTPS > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / targetTPS))
+		defer ticker.Stop()
+	}
+
+	broken := make([]bool, senders)
+	for txIndex := 0; txIndex < txsPerSender; txIndex++ {
+		for sender := 0; sender < senders; sender++ {
+			if broken[sender] {
+				continue
+			}
+			if ticker != nil {
+				<-ticker.C
+			}
+
+			issuedAt := time.Now()
+			if err := issue(sender, txIndex); err != nil {
+				logrus.Debugf("Failed to issue %v", err)
+				rec.issueFailed()
+				broken[sender] = true
+				continue
+			}
+			rec.issuedTx(issuedAt)
+			issued[sender] <- issuedTx{txIndex: txIndex, issuedAt: issuedAt}
+		}
+	}
+	for _, txs := range issued {
+		close(txs)
+	}
+
+	wg.Wait()
+	return rec
+}
@@ -0,0 +1,97 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package loadgen
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunLoad(t *testing.T) {
+	const senders, txsPerSender = 3, 4
+	const brokenSender, refusedTx = 1, 2
+
+	var lock sync.Mutex
+	var issueOrder [][2]int
+	awaited := make([][]int, senders)
+
+	issue := func(sender, txIndex int) error {
+		lock.Lock()
+		defer lock.Unlock()
+		issueOrder = append(issueOrder, [2]int{sender, txIndex})
+		if sender == brokenSender && txIndex == refusedTx {
+			return errors.New("refused")
+		}
+		return nil
+	}
+	await := func(sender, txIndex int, issuedAt time.Time, rec *recorder) {
+		lock.Lock()
+		awaited[sender] = append(awaited[sender], txIndex)
+		lock.Unlock()
+		rec.accepted(issuedAt, issuedAt.Add(time.Millisecond))
+	}
+
+	report := runLoad(senders, txsPerSender, 0, issue, await).report("test")
+
+	// the nth transaction of every sender is issued before the next ones, the broken sender stops being issued
+	var expectedOrder [][2]int
+	for txIndex := 0; txIndex < txsPerSender; txIndex++ {
+		for sender := 0; sender < senders; sender++ {
+			if sender == brokenSender && txIndex > refusedTx {
+				continue
+			}
+			expectedOrder = append(expectedOrder, [2]int{sender, txIndex})
+		}
+	}
+	if len(issueOrder) != len(expectedOrder) {
+		t.Fatalf("expected %d issuances, got %d", len(expectedOrder), len(issueOrder))
+	}
+	for i := range expectedOrder {
+		if issueOrder[i] != expectedOrder[i] {
+			t.Fatalf("issuance %d is %v, expected %v", i, issueOrder[i], expectedOrder[i])
+		}
+	}
+
+	// each sender awaits its issued transactions in order
+	for sender, txIndices := range awaited {
+		expectedTxs := txsPerSender
+		if sender == brokenSender {
+			expectedTxs = refusedTx
+		}
+		if len(txIndices) != expectedTxs {
+			t.Fatalf("sender %d awaited %d transactions, expected %d", sender, len(txIndices), expectedTxs)
+		}
+		for i, txIndex := range txIndices {
+			if txIndex != i {
+				t.Fatalf("sender %d awaited transaction %d in position %d", sender, txIndex, i)
+			}
+		}
+	}
+
+	issued := senders*txsPerSender - (txsPerSender - refusedTx)
+	if report.Issued != issued || report.IssueFailures != 1 || report.Accepted != issued {
+		t.Fatalf("unexpected counts %+v", report)
+	}
+}
+
+func TestRunLoadTargetTPS(t *testing.T) {
+	const senders, txsPerSender, targetTPS = 2, 5, 100
+
+	issue := func(sender, txIndex int) error { return nil }
+	await := func(sender, txIndex int, issuedAt time.Time, rec *recorder) {
+		rec.accepted(issuedAt, issuedAt)
+	}
+
+	start := time.Now()
+	report := runLoad(senders, txsPerSender, targetTPS, issue, await).report("test")
+	// every issuance waits for a tick
+	if elapsed, minimum := time.Since(start), senders*txsPerSender*time.Second/targetTPS; elapsed < minimum {
+		t.Fatalf("issued %d transactions in %v, expected at least %v at %d TPS", senders*txsPerSender, elapsed, minimum, targetTPS)
+	}
+	if report.Issued != senders*txsPerSender || report.Accepted != senders*txsPerSender {
+		t.Fatalf("unexpected counts %+v", report)
+	}
+}
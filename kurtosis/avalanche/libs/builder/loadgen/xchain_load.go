@@ -0,0 +1,199 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package loadgen
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/txhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/wallet"
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	helpers "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/codecs"
+)
+
+const defaultPollInterval = 100 * time.Millisecond
+
+// XChainLoadConfig configures an X Chain load run
+type XChainLoadConfig struct {
+	// Wallets is the number of funded keys, each one issues a chain of TxsPerWallet transactions spending
+	// the output of the previous one
+	Wallets      int
+	TxsPerWallet int
+	// Amount funds each wallet, it must pay the fee of each of its transactions
+	Amount uint64
+	TxFee  uint64
+	// TargetTPS is the issuance rate over all the nodes, 0 issues as fast as possible
+	TargetTPS float64
+	// Timeout is how long each transaction has to be accepted after being issued
+	Timeout time.Duration
+	// PollInterval is how often the status of the transactions is polled, it bounds the latency precision
+	PollInterval time.Duration
+}
+
+// XChainLoadGenerator issues pre-built chains of X Chain transactions across the nodes of a network at a target rate
+// and reports the accepted TPS and the finality latency
+type XChainLoadGenerator struct {
+	chainIDs constants.ChainIDs
+	clients  []*avalanchegoclient.Client
+	config   XChainLoadConfig
+	codec    codec.Manager
+	chains   []*txChain
+}
+
+// txChain is a chain of transactions issued, in order, to the same node
+type txChain struct {
+	client  *avalanchegoclient.Client
+	txBytes [][]byte
+	txIDs   []ids.ID
+}
+
+// NewXChainLoadGenerator creates a load generator issuing to the X Chain of the [clients], one per node
+func NewXChainLoadGenerator(chainIDs constants.ChainIDs, clients []*avalanchegoclient.Client, config XChainLoadConfig) (*XChainLoadGenerator, error) {
+	if len(clients) == 0 {
+		return nil, stacktrace.NewError("The load generator needs at least one client")
+	}
+	if config.Wallets <= 0 || config.TxsPerWallet <= 0 {
+		return nil, stacktrace.NewError("The load generator needs wallets and transactions, got %d wallets of %d transactions",
+			config.Wallets, config.TxsPerWallet)
+	}
+	if config.Timeout == 0 {
+		config.Timeout = constants.TimeoutDuration
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = defaultPollInterval
+	}
+
+	xCodec, err := helpers.CreateXChainCodec()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to create the X Chain codec")
+	}
+	return &XChainLoadGenerator{
+		chainIDs: chainIDs,
+		clients:  clients,
+		config:   config,
+		codec:    xCodec,
+	}, nil
+}
+
+// Prepare funds the wallets from [genesis] and pre-builds their chains of transactions
+// the chains are spread over the nodes, each one is issued to a single node
+func (g *XChainLoadGenerator) Prepare(genesis *topology.Genesis) error {
+	factory := crypto.FactorySECP256K1R{}
+	wallets := make([]*wallet.Wallet, 0, g.config.Wallets)
+	addresses := make([]string, 0, g.config.Wallets)
+	for i := 0; i < g.config.Wallets; i++ {
+		key, err := factory.NewPrivateKey()
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to generate a wallet key")
+		}
+		w, err := wallet.New(g.chainIDs, g.config.TxFee, key.(*crypto.PrivateKeySECP256K1R))
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to create a wallet")
+		}
+		wallets = append(wallets, w)
+		addresses = append(addresses, w.XAddress)
+	}
+
+	if err := genesis.TryMultipleFundXChainAddresses(addresses, g.config.Amount, 1); err != nil {
+		return stacktrace.Propagate(err, "Failed to fund the wallets")
+	}
+
+	g.chains = make([]*txChain, 0, len(wallets))
+	for i, w := range wallets {
+		client := g.clients[i%len(g.clients)]
+		utxos, err := g.awaitFunding(client, w)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get the UTXOs of wallet %s", w.XAddress)
+		}
+		if len(utxos) != 1 {
+			return stacktrace.NewError("Wallet %s has %d UTXOs, expected the funding one", w.XAddress, len(utxos))
+		}
+
+		txBytes, txIDs, err := txhelper.CreateConsecutiveTransactions(g.chainIDs, utxos[0], uint64(g.config.TxsPerWallet),
+			g.config.Amount, g.config.TxFee, w.Keys()[0], g.codec)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to build the transactions of wallet %s", w.XAddress)
+		}
+		g.chains = append(g.chains, &txChain{
+			client:  client,
+			txBytes: txBytes,
+			txIDs:   txIDs,
+		})
+	}
+	logrus.Infof("Prepared %d chains of %d X Chain transactions.", len(g.chains), g.config.TxsPerWallet)
+	return nil
+}
+
+// awaitFunding returns the UTXOs of [w] once [client] sees the funding one, the funding transaction was only
+// awaited on the genesis node and may not be accepted by [client] yet
+func (g *XChainLoadGenerator) awaitFunding(client *avalanchegoclient.Client, w *wallet.Wallet) ([]*avax.UTXO, error) {
+	for startTime := time.Now(); ; time.Sleep(g.config.PollInterval) {
+		utxos, err := w.XUTXOs(client)
+		if err != nil {
+			return nil, err
+		}
+		if len(utxos) > 0 {
+			return utxos, nil
+		}
+		if time.Since(startTime) > g.config.Timeout {
+			return nil, stacktrace.NewError("Timed out waiting for the funding of wallet %s", w.XAddress)
+		}
+	}
+}
+
+// Run issues the prepared transactions at the target rate, the nth transaction of every chain before the next ones,
+// waits for them to be decided and returns the Report of the run
+// A chain stops being issued after one of its transactions is refused, the next ones spend its outputs
+func (g *XChainLoadGenerator) Run() (*Report, error) {
+	if len(g.chains) == 0 {
+		return nil, stacktrace.NewError("The load generator was not prepared")
+	}
+
+	issue := func(sender, txIndex int) error {
+		chain := g.chains[sender]
+		if _, err := chain.client.XChainAPI().IssueTx(chain.txBytes[txIndex]); err != nil {
+			return fmt.Errorf("transaction %s: %w", chain.txIDs[txIndex], err)
+		}
+		return nil
+	}
+	await := func(sender, txIndex int, issuedAt time.Time, rec *recorder) {
+		chain := g.chains[sender]
+		g.awaitDecision(chain.client, chain.txIDs[txIndex], issuedAt, rec)
+	}
+	rec := runLoad(len(g.chains), g.config.TxsPerWallet, g.config.TargetTPS, issue, await)
+	return rec.report("X Chain"), nil
+}
+
+// awaitDecision polls the status of [txID], issued at [issuedAt], until it's decided or times out
+func (g *XChainLoadGenerator) awaitDecision(client *avalanchegoclient.Client, txID ids.ID, issuedAt time.Time, rec *recorder) {
+	for {
+		status, err := client.XChainAPI().GetTxStatus(txID)
+		if err != nil {
+			logrus.Debugf("Failed to get the status of transaction %s: %v", txID, err)
+		}
+		switch {
+		case status == choices.Accepted:
+			rec.accepted(issuedAt, time.Now())
+			return
+		case status == choices.Rejected:
+			rec.rejectedTx()
+			return
+		case time.Since(issuedAt) > g.config.Timeout:
+			rec.timedOutTx()
+			return
+		}
+		time.Sleep(g.config.PollInterval)
+	}
+}
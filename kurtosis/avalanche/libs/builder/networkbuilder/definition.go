@@ -0,0 +1,301 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networkbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants/certs"
+	"github.com/palantir/stacktrace"
+	"gopkg.in/yaml.v2"
+)
+
+// NetworkDefinition is the file representation of a Network
+// It can be written in JSON or YAML, for example:
+//
+//	image: avaplatform/avalanchego:v1.3.2
+//	txFee: 1000000
+//	snowSampleSize: 3
+//	snowQuorumSize: 3
+//	nodes:
+//	  - id: bootstrapNode-1
+//	    bootstrap: true
+//	    staking: true
+//	    genesisStaker: 1
+//	  - id: api-node
+//	    image: avaplatform/avalanchego:v1.3.1
+//	    flags:
+//	      --api-admin-enabled: "true"
+//	    cChainConfig:
+//	      debug-api-enabled: true
+//
+// Bootstrap nodes must be named bootstrapNode-1..N, in order, and use one of the
+// DefaultLocalNetGenesisConfig stakers so their NodeIDs are known in advance.
+// Every other node bootstraps from all the bootstrap nodes.
+type NetworkDefinition struct {
+	Image          string            `json:"image" yaml:"image"`
+	TxFee          uint64            `json:"txFee" yaml:"txFee"`
+	SnowSampleSize int               `json:"snowSampleSize" yaml:"snowSampleSize"`
+	SnowQuorumSize int               `json:"snowQuorumSize" yaml:"snowQuorumSize"`
+	Nodes          []*NodeDefinition `json:"nodes" yaml:"nodes"`
+}
+
+// NodeDefinition is the file representation of a Node
+type NodeDefinition struct {
+	ID        string `json:"id" yaml:"id"`
+	Image     string `json:"image" yaml:"image"`
+	Staking   bool   `json:"staking" yaml:"staking"`
+	Bootstrap bool   `json:"bootstrap" yaml:"bootstrap"`
+	// GenesisStaker is the 1-based index of the DefaultLocalNetGenesisConfig staker whose certs the node uses
+	// 0 lets the node generate its own certs
+	GenesisStaker int `json:"genesisStaker" yaml:"genesisStaker"`
+	// GenerateCerts generates the node certs up front, so its NodeID is known before it's launched
+	GenerateCerts bool `json:"generateCerts" yaml:"generateCerts"`
+	// LogLevel and the snow sizes override the node defaults, the snow sizes default to the network ones
+	// NetworkInitialTimeout (e.g. 5s) and BootstrapAttempts are only passed to avalanchego when set
+	LogLevel              constants.AvalancheLogLevel `json:"logLevel" yaml:"logLevel"`
+	SnowSampleSize        int                         `json:"snowSampleSize" yaml:"snowSampleSize"`
+	SnowQuorumSize        int                         `json:"snowQuorumSize" yaml:"snowQuorumSize"`
+	NetworkInitialTimeout string                      `json:"networkInitialTimeout" yaml:"networkInitialTimeout"`
+	BootstrapAttempts     *int                        `json:"bootstrapAttempts" yaml:"bootstrapAttempts"`
+	// RequiredChains, RequiredSubnets and MinPeers are required for the node to be available, on top of it being
+	// healthy with the P, X and C Chains bootstrapped
+	RequiredChains  []string `json:"requiredChains" yaml:"requiredChains"`
+	RequiredSubnets []string `json:"requiredSubnets" yaml:"requiredSubnets"`
+	MinPeers        int      `json:"minPeers" yaml:"minPeers"`
+	// Flags are extra avalanchego flags, keyed by flag name (e.g. --api-admin-enabled)
+	Flags map[string]string `json:"flags" yaml:"flags"`
+	// Config is merged into the node config file, CChainConfig overrides the default coreth config
+	Config       map[string]interface{} `json:"config" yaml:"config"`
+	CChainConfig map[string]interface{} `json:"cChainConfig" yaml:"cChainConfig"`
+	// Byzantine marks the node as adversarial
+	Byzantine bool `json:"byzantine" yaml:"byzantine"`
+}
+
+// LoadNetworkDefinition reads the NetworkDefinition at [path]
+// The format is chosen from the file extension: .json, .yaml or .yml
+func LoadNetworkDefinition(path string) (*NetworkDefinition, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to read network definition %s", path)
+	}
+
+	definition, err := ParseNetworkDefinition(data, filepath.Ext(path))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to parse network definition %s", path)
+	}
+	return definition, nil
+}
+
+// ParseNetworkDefinition parses [data] as a NetworkDefinition written in [format] (json, yaml or yml)
+// Unknown fields are rejected in both formats, so a misspelled field can't be silently ignored
+func ParseNetworkDefinition(data []byte, format string) (*NetworkDefinition, error) {
+	definition := &NetworkDefinition{}
+	switch strings.TrimPrefix(strings.ToLower(format), ".") {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(definition); err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to unmarshal JSON network definition")
+		}
+		if decoder.More() {
+			return nil, stacktrace.NewError("Unexpected data after the JSON network definition")
+		}
+	case "yaml", "yml":
+		if err := yaml.UnmarshalStrict(data, definition); err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to unmarshal YAML network definition")
+		}
+	default:
+		return nil, stacktrace.NewError("Unknown network definition format %s", format)
+	}
+	return definition, nil
+}
+
+// Validate checks the NetworkDefinition can be turned into a Network
+func (d *NetworkDefinition) Validate() error {
+	if len(d.Nodes) == 0 {
+		return stacktrace.NewError("Network definition has no nodes")
+	}
+	if d.SnowSampleSize <= 0 || d.SnowQuorumSize <= 0 {
+		return stacktrace.NewError("Snow sample size (%d) and quorum size (%d) must be positive", d.SnowSampleSize, d.SnowQuorumSize)
+	}
+	if d.SnowQuorumSize > d.SnowSampleSize {
+		return stacktrace.NewError("Snow quorum size (%d) can't be greater than the sample size (%d)", d.SnowQuorumSize, d.SnowSampleSize)
+	}
+
+	ids := map[string]bool{}
+	stakers := map[int]string{}
+	numBootstrapNodes := 0
+	for i, node := range d.Nodes {
+		if node.ID == "" {
+			return stacktrace.NewError("Node %d has no id", i)
+		}
+		if ids[node.ID] {
+			return stacktrace.NewError("Node %s is defined more than once", node.ID)
+		}
+		ids[node.ID] = true
+
+		if node.GenesisStaker < 0 || node.GenesisStaker > len(constants.DefaultLocalNetGenesisConfig.Stakers) {
+			return stacktrace.NewError("Node %s uses genesis staker %d, expected 1 to %d",
+				node.ID, node.GenesisStaker, len(constants.DefaultLocalNetGenesisConfig.Stakers))
+		}
+
+		if node.Bootstrap {
+			numBootstrapNodes++
+			if expectedID := fmt.Sprintf("bootstrapNode-%d", numBootstrapNodes); node.ID != expectedID {
+				return stacktrace.NewError("Bootstrap node %s should be named %s", node.ID, expectedID)
+			}
+			if node.GenesisStaker == 0 {
+				return stacktrace.NewError("Bootstrap node %s must use a genesis staker", node.ID)
+			}
+			if otherNode, ok := stakers[node.GenesisStaker]; ok {
+				return stacktrace.NewError("Bootstrap nodes %s and %s use the same genesis staker %d", otherNode, node.ID, node.GenesisStaker)
+			}
+			stakers[node.GenesisStaker] = node.ID
+		}
+
+		if node.NetworkInitialTimeout != "" {
+			if _, err := time.ParseDuration(node.NetworkInitialTimeout); err != nil {
+				return stacktrace.Propagate(err, "Node %s network initial timeout %s is not a duration", node.ID, node.NetworkInitialTimeout)
+			}
+		}
+
+		for flag, value := range node.Flags {
+			if !strings.HasPrefix(flag, "--") {
+				return stacktrace.NewError("Node %s flag %s must start with --", node.ID, flag)
+			}
+			if flag == httpPortFlag || flag == stakingPortFlag {
+				if _, err := strconv.Atoi(value); err != nil {
+					return stacktrace.Propagate(err, "Node %s flag %s must be a port number", node.ID, flag)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Build validates the NetworkDefinition and creates the Network it describes
+// [defaultImage] is used by nodes when neither the node nor the definition set an image
+func (d *NetworkDefinition) Build(defaultImage string) (*Network, error) {
+	if err := d.Validate(); err != nil {
+		return nil, stacktrace.Propagate(err, "Invalid network definition")
+	}
+
+	networkImage := d.Image
+	if networkImage == "" {
+		networkImage = defaultImage
+	}
+
+	network := New().
+		Image(networkImage).
+		SnowSize(d.SnowSampleSize, d.SnowQuorumSize)
+	if d.TxFee != 0 {
+		network.TxFee(d.TxFee)
+	}
+
+	// bootstrap nodes go first, each one connecting to the previous ones
+	bootstrapNodeID := 1
+	for _, nodeDefinition := range d.Nodes {
+		if !nodeDefinition.Bootstrap {
+			continue
+		}
+		staker := constants.DefaultLocalNetGenesisConfig.Stakers[nodeDefinition.GenesisStaker-1]
+		network.AddNode(nodeDefinition.build(networkImage).
+			BootstrapNode(true).
+			BootstrapNodeID(bootstrapNodeID))
+		network.ConnectedBTNodeIDs(staker.NodeID)
+		bootstrapNodeID++
+	}
+
+	for _, nodeDefinition := range d.Nodes {
+		if !nodeDefinition.Bootstrap {
+			network.AddNode(nodeDefinition.build(networkImage))
+		}
+	}
+
+	network.HasBootstrapNodes(bootstrapNodeID > 1)
+	if err := network.Validate(); err != nil {
+		return nil, stacktrace.Propagate(err, "Invalid network definition")
+	}
+	return network, nil
+}
+
+func (d *NodeDefinition) build(networkImage string) *Node {
+	image := d.Image
+	if image == "" {
+		image = networkImage
+	}
+
+	node := NewNode(d.ID).
+		Image(image).
+		IsStaking(d.Staking).
+		Byzantine(d.Byzantine)
+	if d.GenesisStaker != 0 {
+		staker := constants.DefaultLocalNetGenesisConfig.Stakers[d.GenesisStaker-1]
+		node.PrivateKey(staker.PrivateKey).
+			TLSCert(staker.TLSCert)
+	} else if d.GenerateCerts {
+		node.CertProvider(certs.NewRandomAvalancheCertProvider(true))
+	}
+	if d.LogLevel != "" {
+		node.LogLevel(d.LogLevel)
+	}
+	node.SnowSize(d.SnowSampleSize, d.SnowQuorumSize)
+	if d.NetworkInitialTimeout != "" {
+		// validated in NetworkDefinition.Validate
+		timeout, _ := time.ParseDuration(d.NetworkInitialTimeout)
+		node.NetworkInitialTimeout(timeout)
+	}
+	if d.BootstrapAttempts != nil {
+		node.BootstrapAttempts(*d.BootstrapAttempts)
+	}
+	node.RequireBootstrappedChains(d.RequiredChains...).
+		RequireBootstrappedSubnets(d.RequiredSubnets...).
+		MinPeers(d.MinPeers)
+	for flag, value := range d.Flags {
+		node.Flag(flag, value)
+	}
+	for key, value := range d.Config {
+		node.Config(key, stringKeys(value))
+	}
+	for key, value := range d.CChainConfig {
+		node.CChainConfig(key, stringKeys(value))
+	}
+	return node
+}
+
+// stringKeys converts the map[interface{}]interface{} values created by the YAML parser
+// into map[string]interface{} so they can be written as JSON
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, nested := range v {
+			converted[fmt.Sprint(key)] = stringKeys(nested)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, nested := range v {
+			converted[key] = stringKeys(nested)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, nested := range v {
+			converted[i] = stringKeys(nested)
+		}
+		return converted
+	default:
+		return value
+	}
+}
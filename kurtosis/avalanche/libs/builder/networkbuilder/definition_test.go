@@ -0,0 +1,175 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networkbuilder
+
+import (
+	"testing"
+	"time"
+)
+
+const validJSONDefinition = `{
+	"image": "avaplatform/avalanchego:v1.3.2",
+	"txFee": 1000000,
+	"snowSampleSize": 2,
+	"snowQuorumSize": 2,
+	"nodes": [
+		{"id": "bootstrapNode-1", "bootstrap": true, "staking": true, "genesisStaker": 1},
+		{"id": "bootstrapNode-2", "bootstrap": true, "staking": true, "genesisStaker": 2},
+		{
+			"id": "api-node",
+			"image": "avaplatform/avalanchego:v1.3.1",
+			"networkInitialTimeout": "5s",
+			"bootstrapAttempts": 3,
+			"minPeers": 2,
+			"flags": {"--api-admin-enabled": "true", "--http-port": "9660"},
+			"cChainConfig": {"debug-api-enabled": true}
+		}
+	]
+}`
+
+const validYAMLDefinition = `
+image: avaplatform/avalanchego:v1.3.2
+txFee: 1000000
+snowSampleSize: 2
+snowQuorumSize: 2
+nodes:
+  - id: bootstrapNode-1
+    bootstrap: true
+    staking: true
+    genesisStaker: 1
+  - id: bootstrapNode-2
+    bootstrap: true
+    staking: true
+    genesisStaker: 2
+  - id: api-node
+    image: avaplatform/avalanchego:v1.3.1
+    networkInitialTimeout: 5s
+    bootstrapAttempts: 3
+    minPeers: 2
+    flags:
+      --api-admin-enabled: "true"
+      --http-port: "9660"
+    cChainConfig:
+      debug-api-enabled: true
+`
+
+func TestParseNetworkDefinition(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{name: "json", data: validJSONDefinition, format: "json"},
+		{name: "json extension", data: validJSONDefinition, format: ".JSON"},
+		{name: "yaml", data: validYAMLDefinition, format: "yaml"},
+		{name: "yml extension", data: validYAMLDefinition, format: ".yml"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			definition, err := ParseNetworkDefinition([]byte(test.data), test.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			network, err := definition.Build("default-image")
+			if err != nil {
+				t.Fatalf("unexpected build error: %v", err)
+			}
+			if len(network.Nodes) != 3 {
+				t.Fatalf("expected 3 nodes, got %d", len(network.Nodes))
+			}
+			if image := network.Nodes["bootstrapNode-1"].GetImage(); image != "avaplatform/avalanchego:v1.3.2" {
+				t.Errorf("expected the bootstrap node to use the network image, got %s", image)
+			}
+			if !network.Nodes["bootstrapNode-2"].IsBootstrapNode() || network.Nodes["bootstrapNode-2"].GetBootstrapNodeID() != 2 {
+				t.Errorf("expected bootstrapNode-2 to be the second bootstrap node")
+			}
+
+			node := network.Nodes["api-node"]
+			if image := node.GetImage(); image != "avaplatform/avalanchego:v1.3.1" {
+				t.Errorf("expected the node image to override the network one, got %s", image)
+			}
+			if timeout := node.GetNetworkInitialTimeout(); timeout != 5*time.Second {
+				t.Errorf("expected a 5s network initial timeout, got %s", timeout)
+			}
+			if attempts := node.GetBootstrapAttempts(); attempts != 3 {
+				t.Errorf("expected 3 bootstrap attempts, got %d", attempts)
+			}
+			if minPeers := node.GetMinPeers(); minPeers != 2 {
+				t.Errorf("expected 2 min peers, got %d", minPeers)
+			}
+			if port, err := node.GetHTTPPort(); err != nil || port != 9660 {
+				t.Errorf("expected the HTTP port flag to be used, got %d (%v)", port, err)
+			}
+			if enabled, ok := node.GetCChainConfig()["debug-api-enabled"]; !ok || enabled != true {
+				t.Errorf("expected the C Chain config to be set, got %v", node.GetCChainConfig())
+			}
+		})
+	}
+}
+
+func TestParseNetworkDefinitionErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{name: "unknown format", data: validJSONDefinition, format: "toml"},
+		{name: "json unknown field", data: `{"snowSampleSize": 2, "snowQuorumSize": 2, "nodes": [], "imgae": "typo"}`, format: "json"},
+		{name: "json unknown node field", data: `{"nodes": [{"id": "node", "stakin": true}]}`, format: "json"},
+		{name: "json trailing data", data: `{"nodes": []} {"nodes": []}`, format: "json"},
+		{name: "json bad value", data: `{"txFee": "a lot"}`, format: "json"},
+		{name: "json malformed", data: `{"nodes": [`, format: "json"},
+		{name: "yaml unknown field", data: "imgae: typo\n", format: "yaml"},
+		{name: "yaml unknown node field", data: "nodes:\n  - id: node\n    stakin: true\n", format: "yaml"},
+		{name: "yaml bad value", data: "snowSampleSize: many\n", format: "yaml"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ParseNetworkDefinition([]byte(test.data), test.format); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestNetworkDefinitionValidate(t *testing.T) {
+	bootstrapNode := func(id string, staker int) *NodeDefinition {
+		return &NodeDefinition{ID: id, Bootstrap: true, Staking: true, GenesisStaker: staker}
+	}
+	tests := []struct {
+		name  string
+		nodes []*NodeDefinition
+		valid bool
+	}{
+		{name: "valid", nodes: []*NodeDefinition{bootstrapNode("bootstrapNode-1", 1), {ID: "node"}}, valid: true},
+		{name: "no nodes", nodes: nil},
+		{name: "no id", nodes: []*NodeDefinition{{}}},
+		{name: "duplicate id", nodes: []*NodeDefinition{{ID: "node"}, {ID: "node"}}},
+		{name: "unknown genesis staker", nodes: []*NodeDefinition{{ID: "node", GenesisStaker: 100}}},
+		{name: "misnamed bootstrap node", nodes: []*NodeDefinition{bootstrapNode("bootstrapNode-2", 1)}},
+		{name: "bootstrap node without staker", nodes: []*NodeDefinition{bootstrapNode("bootstrapNode-1", 0)}},
+		{name: "shared genesis staker", nodes: []*NodeDefinition{bootstrapNode("bootstrapNode-1", 1), bootstrapNode("bootstrapNode-2", 1)}},
+		{name: "bad network initial timeout", nodes: []*NodeDefinition{{ID: "node", NetworkInitialTimeout: "soon"}}},
+		{name: "flag without dashes", nodes: []*NodeDefinition{{ID: "node", Flags: map[string]string{"api-admin-enabled": "true"}}}},
+		{name: "bad port flag", nodes: []*NodeDefinition{{ID: "node", Flags: map[string]string{httpPortFlag: "http"}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			definition := &NetworkDefinition{SnowSampleSize: 1, SnowQuorumSize: 1, Nodes: test.nodes}
+			err := definition.Validate()
+			if test.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	definition := &NetworkDefinition{SnowSampleSize: 1, SnowQuorumSize: 2, Nodes: []*NodeDefinition{{ID: "node"}}}
+	if err := definition.Validate(); err == nil {
+		t.Error("expected an error for a quorum greater than the sample size")
+	}
+}
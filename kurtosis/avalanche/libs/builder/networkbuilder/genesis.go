@@ -0,0 +1,279 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networkbuilder
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/formatters"
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/evm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// same as the avalanchego local network
+	defaultInitialStakeDuration       = 365 * 24 * time.Hour
+	defaultInitialStakeDurationOffset = 90 * time.Minute
+)
+
+// Genesis builds a custom genesis, passed to the nodes with --genesis, instead of the hard-coded local network one
+// The genesis is built once, the first time it's needed, so every node is launched with the same genesis
+type Genesis struct {
+	networkID                  uint32
+	fundedKey                  string
+	stakers                    []*Node
+	xAllocations               map[ids.ShortID]uint64
+	pAllocations               map[ids.ShortID][]genesis.LockedAmount
+	stakedAmount               uint64
+	cAllocations               map[common.Address]*big.Int
+	initialStakeDuration       time.Duration
+	initialStakeDurationOffset time.Duration
+	message                    string
+
+	buildOnce     sync.Once
+	genesisJSON   []byte
+	genesisConfig *constants.NetworkGenesisConfig
+	buildErr      error
+}
+
+// NewGenesis creates the builder of a genesis for the network [networkID]
+// [fundedKey] (e.g. PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN) owns the staked funds
+// and the stakers rewards, it's the key imported by the topology Genesis
+// [networkID] can't be one of the standard networks (mainnet, fuji or local) as avalanchego ignores their --genesis
+func NewGenesis(networkID uint32, fundedKey string) *Genesis {
+	return &Genesis{
+		networkID:                  networkID,
+		fundedKey:                  fundedKey,
+		xAllocations:               map[ids.ShortID]uint64{},
+		pAllocations:               map[ids.ShortID][]genesis.LockedAmount{},
+		cAllocations:               map[common.Address]*big.Int{},
+		initialStakeDuration:       defaultInitialStakeDuration,
+		initialStakeDurationOffset: defaultInitialStakeDurationOffset,
+		message:                    "avalanchego-kurtosis",
+	}
+}
+
+// Staker adds [node] to the genesis validators, the node must have its certs so its NodeID is known
+func (g *Genesis) Staker(node *Node) *Genesis {
+	g.stakers = append(g.stakers, node)
+	return g
+}
+
+// XAllocation funds [address] with [amount] nAVAX on the X Chain
+func (g *Genesis) XAllocation(address ids.ShortID, amount uint64) *Genesis {
+	g.xAllocations[address] += amount
+	return g
+}
+
+// PAllocation funds [address] with [amount] nAVAX on the P Chain, locked until [locktime] (unix time, 0 to unlock it)
+func (g *Genesis) PAllocation(address ids.ShortID, amount uint64, locktime uint64) *Genesis {
+	g.pAllocations[address] = append(g.pAllocations[address], genesis.LockedAmount{Amount: amount, Locktime: locktime})
+	return g
+}
+
+// StakedAmount sets the nAVAX the funded key stakes, split between the stakers
+func (g *Genesis) StakedAmount(amount uint64) *Genesis {
+	g.stakedAmount = amount
+	return g
+}
+
+// CAllocation funds [address] with [amount] wei on the C Chain
+func (g *Genesis) CAllocation(address common.Address, amount *big.Int) *Genesis {
+	g.cAllocations[address] = amount
+	return g
+}
+
+// StakingDurations sets how long the stakers validate, each staker stops validating [offset] before the previous one
+func (g *Genesis) StakingDurations(duration time.Duration, offset time.Duration) *Genesis {
+	g.initialStakeDuration = duration
+	g.initialStakeDurationOffset = offset
+	return g
+}
+
+func (g *Genesis) Message(message string) *Genesis {
+	g.message = message
+	return g
+}
+
+func (g *Genesis) GetNetworkID() uint32 {
+	return g.networkID
+}
+
+// GetJSON returns the genesis file contents
+func (g *Genesis) GetJSON() ([]byte, error) {
+	g.buildOnce.Do(g.build)
+	return g.genesisJSON, g.buildErr
+}
+
+// GetGenesisConfig returns the stakers, the funded key and the chain IDs derived from the genesis
+func (g *Genesis) GetGenesisConfig() (*constants.NetworkGenesisConfig, error) {
+	g.buildOnce.Do(g.build)
+	return g.genesisConfig, g.buildErr
+}
+
+func (g *Genesis) build() {
+	g.genesisJSON, g.genesisConfig, g.buildErr = g.tryBuild()
+}
+
+func (g *Genesis) tryBuild() ([]byte, *constants.NetworkGenesisConfig, error) {
+	switch g.networkID {
+	case avalancheconstants.MainnetID, avalancheconstants.FujiID, avalancheconstants.LocalID:
+		return nil, nil, stacktrace.NewError("Network %d is a standard network, its genesis can't be replaced", g.networkID)
+	}
+	if len(g.stakers) == 0 {
+		return nil, nil, stacktrace.NewError("Genesis has no stakers")
+	}
+	if g.stakedAmount == 0 {
+		return nil, nil, stacktrace.NewError("Genesis has no staked amount")
+	}
+	if time.Duration(len(g.stakers)-1)*g.initialStakeDurationOffset > g.initialStakeDuration {
+		return nil, nil, stacktrace.NewError("Initial stake duration %v is too short for %d stakers with an offset of %v",
+			g.initialStakeDuration, len(g.stakers), g.initialStakeDurationOffset)
+	}
+
+	fundedPrivateKey, err := formatters.ConvertFormattedPrivateKey(g.fundedKey)
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "Invalid funded key")
+	}
+	fundedAddress := fundedPrivateKey.PublicKey().Address()
+	formattedFundedAddress, err := formatting.FormatAddress("X", avalancheconstants.GetHRP(g.networkID), fundedAddress.Bytes())
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "Failed to format the funded address")
+	}
+
+	genesisConfig := &constants.NetworkGenesisConfig{
+		FundedAddresses: constants.FundedAddress{
+			Address:    formattedFundedAddress,
+			PrivateKey: g.fundedKey,
+		},
+	}
+
+	config := genesis.Config{
+		NetworkID:                  g.networkID,
+		StartTime:                  uint64(time.Now().Unix()),
+		InitialStakeDuration:       uint64(g.initialStakeDuration.Seconds()),
+		InitialStakeDurationOffset: uint64(g.initialStakeDurationOffset.Seconds()),
+		InitialStakedFunds:         []ids.ShortID{fundedAddress},
+		Message:                    g.message,
+	}
+
+	// the staked funds are the funded key P Chain allocation, the other allocations are added as is
+	config.Allocations = append(config.Allocations, genesis.Allocation{
+		AVAXAddr:       fundedAddress,
+		InitialAmount:  g.xAllocations[fundedAddress],
+		UnlockSchedule: []genesis.LockedAmount{{Amount: g.stakedAmount}},
+	})
+	addresses := map[ids.ShortID]bool{}
+	for address := range g.xAllocations {
+		addresses[address] = true
+	}
+	for address := range g.pAllocations {
+		addresses[address] = true
+	}
+	for address := range addresses {
+		if address == fundedAddress {
+			if len(g.pAllocations[address]) != 0 {
+				return nil, nil, stacktrace.NewError("The funded key P Chain funds are staked, it can't have a P Chain allocation")
+			}
+			continue
+		}
+		config.Allocations = append(config.Allocations, genesis.Allocation{
+			AVAXAddr:       address,
+			InitialAmount:  g.xAllocations[address],
+			UnlockSchedule: g.pAllocations[address],
+		})
+	}
+
+	for _, node := range g.stakers {
+		nodeID, err := node.GetNodeID()
+		if err != nil {
+			return nil, nil, stacktrace.Propagate(err, "Staker %s has no NodeID", node.ID)
+		}
+		shortNodeID, err := ids.ShortFromPrefixedString(nodeID, avalancheconstants.NodeIDPrefix)
+		if err != nil {
+			return nil, nil, stacktrace.Propagate(err, "Invalid NodeID %s", nodeID)
+		}
+
+		config.InitialStakers = append(config.InitialStakers, genesis.Staker{
+			NodeID:        shortNodeID,
+			RewardAddress: fundedAddress,
+			DelegationFee: 1000000, // 100%, same as the local network
+		})
+		genesisConfig.Stakers = append(genesisConfig.Stakers, constants.StakerIdentity{
+			NodeID:     nodeID,
+			PrivateKey: node.GetPrivateKey(),
+			TLSCert:    node.GetTLSCert(),
+		})
+	}
+
+	config.CChainGenesis, err = g.cChainGenesis()
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "Failed to create the C Chain genesis")
+	}
+
+	genesisBytes, avaxAssetID, err := genesis.FromConfig(&config)
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "Failed to build the genesis")
+	}
+	xChain, err := genesis.VMGenesis(genesisBytes, avm.ID)
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "Failed to find the X Chain in the genesis")
+	}
+	cChain, err := genesis.VMGenesis(genesisBytes, evm.ID)
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "Failed to find the C Chain in the genesis")
+	}
+	genesisConfig.ChainIDs = constants.ChainIDs{
+		NetworkID:       g.networkID,
+		XChainID:        xChain.ID(),
+		PlatformChainID: avalancheconstants.PlatformChainID,
+		CChainID:        cChain.ID(),
+		AvaxAssetID:     avaxAssetID,
+	}
+
+	unparsedConfig, err := config.Unparse()
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "Failed to unparse the genesis config")
+	}
+	genesisJSON, err := json.Marshal(unparsedConfig)
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "Failed to marshal the genesis config")
+	}
+	return genesisJSON, genesisConfig, nil
+}
+
+// cChainGenesis adds the C Chain allocations to the local network C Chain genesis
+func (g *Genesis) cChainGenesis() (string, error) {
+	cChainGenesis := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(genesis.LocalConfig.CChainGenesis), &cChainGenesis); err != nil {
+		return "", stacktrace.Propagate(err, "Failed to unmarshal the local C Chain genesis")
+	}
+
+	alloc, ok := cChainGenesis["alloc"].(map[string]interface{})
+	if !ok {
+		return "", stacktrace.NewError("Local C Chain genesis has no alloc")
+	}
+	for address, amount := range g.cAllocations {
+		alloc[strings.TrimPrefix(strings.ToLower(address.Hex()), "0x")] = map[string]string{
+			"balance": "0x" + amount.Text(16),
+		}
+	}
+
+	cChainGenesisJSON, err := json.Marshal(cChainGenesis)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Failed to marshal the C Chain genesis")
+	}
+	return string(cChainGenesisJSON), nil
+}
@@ -5,11 +5,23 @@ package networkbuilder
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants/certs"
 	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	httpPortFlag    = "--http-port"
+	stakingPortFlag = "--staking-port"
+
+	defaultHTTPPort    = 9650
+	defaultStakingPort = 9651
 )
 
 // Network defines the Network structure of the Nodes in the Topology
@@ -22,6 +34,9 @@ type Network struct {
 	hasBootstrapNodes  bool
 	connectedBTNodeIDs []string
 	connectedBTNodeIPs []string
+	genesis            *Genesis
+	// sharedCertProvider generates the staking cert shared by the nodes that don't vary their certs
+	sharedCertProvider certs.AvalancheCertProvider
 }
 
 // New creates the Network builder
@@ -55,6 +70,13 @@ func (n *Network) AddNode(node *Node) *Network {
 		panic("Node already exist")
 	}
 
+	if !node.varyCerts && !node.HasCerts() {
+		if n.sharedCertProvider == nil {
+			n.sharedCertProvider = certs.NewRandomAvalancheCertProvider(false)
+		}
+		node.CertProvider(n.sharedCertProvider)
+	}
+
 	n.Nodes[node.ID] = node.ConnectedBTNodeIDs(n.GetConnectedBTNodeIDs())
 	return n
 }
@@ -72,7 +94,7 @@ func (n *Network) GetSnowSampleSize() int {
 }
 
 func (n *Network) HasBootstrapNodes(b bool) *Network {
-	n.hasBootstrapNodes = true
+	n.hasBootstrapNodes = b
 	return n
 }
 
@@ -90,10 +112,63 @@ func (n *Network) RemoveNode(node *Node) *Network {
 	return n
 }
 
+// CustomGenesis launches the nodes with [genesis] instead of the local network genesis
+func (n *Network) CustomGenesis(genesis *Genesis) *Network {
+	n.genesis = genesis
+	return n
+}
+
+// GetCustomGenesis returns the custom genesis of the Network, nil if it uses the local network genesis
+func (n *Network) GetCustomGenesis() *Genesis {
+	return n.genesis
+}
+
+// GetGenesisConfig returns the stakers, the funded key and the chain IDs of the Network genesis
+func (n *Network) GetGenesisConfig() (*constants.NetworkGenesisConfig, error) {
+	if n.genesis == nil {
+		return &constants.DefaultLocalNetGenesisConfig, nil
+	}
+	return n.genesis.GetGenesisConfig()
+}
+
+// GetByzantineNodes returns the Nodes marked as Byzantine, sorted by ID
+func (n *Network) GetByzantineNodes() []*Node {
+	return n.getNodes(true)
+}
+
+// GetHonestNodes returns the Nodes not marked as Byzantine, sorted by ID
+func (n *Network) GetHonestNodes() []*Node {
+	return n.getNodes(false)
+}
+
+func (n *Network) getNodes(byzantine bool) []*Node {
+	var nodes []*Node
+	for _, node := range n.Nodes {
+		if node.IsByzantine() == byzantine {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
 func (n *Network) GetNumBootstrapNodes() int {
 	return len(n.connectedBTNodeIDs)
 }
 
+// Validate checks the Network and its Nodes settings don't contradict each other
+func (n *Network) Validate() error {
+	if _, err := n.GetGenesisConfig(); err != nil {
+		return stacktrace.Propagate(err, "Invalid genesis")
+	}
+	for _, node := range n.Nodes {
+		if err := node.validate(n); err != nil {
+			return stacktrace.Propagate(err, "Invalid settings for node %s", node.ID)
+		}
+	}
+	return nil
+}
+
 type Node struct {
 	ID                    string
 	varyCerts             bool
@@ -110,18 +185,29 @@ type Node struct {
 	bootstrapNodeID       int
 	connectedBTNodeIPs    string
 	boostrapAttempts      int
+	certErr               error
+	requiredChains        []string
+	requiredSubnets       []string
+	minPeers              int
+	whitelistedSubnets    []string
+	flags                 map[string]string
+	config                map[string]interface{}
+	cChainConfig          map[string]interface{}
+	dataDir               string
+	isByzantine           bool
 }
 
 func NewNode(nodeID string) *Node {
 	return &Node{
-		ID:                    nodeID,
-		varyCerts:             true,
-		serviceLogLevel:       constants.DEBUG,
-		imageName:             "avaplatform/avalanchego:v1.2.3",
-		snowQuorumSize:        1,
-		snowSampleSize:        1,
-		networkInitialTimeout: 2 * time.Second,
-		boostrapAttempts:      10,
+		ID:              nodeID,
+		varyCerts:       true,
+		serviceLogLevel: constants.DEBUG,
+		imageName:       "avaplatform/avalanchego:v1.2.3",
+		flags:           map[string]string{},
+		config:          map[string]interface{}{},
+		cChainConfig:    map[string]interface{}{},
+		// unique so the nodes of different tests sharing the test volume don't share their database
+		dataDir: fmt.Sprintf("%s-%d", nodeID, time.Now().UnixNano()),
 	}
 }
 
@@ -165,6 +251,30 @@ func (node *Node) HasCerts() bool {
 	return len(node.tlsCert) > 0 && len(node.privateKey) > 0
 }
 
+// CertProvider sets the staking cert and key of the node to the ones generated by [provider]
+// so its NodeID is known before the node is launched
+// Failures are returned by Network.Validate
+func (node *Node) CertProvider(provider certs.AvalancheCertProvider) *Node {
+	cert, key, err := provider.GetCertAndKey()
+	if err != nil {
+		node.certErr = stacktrace.Propagate(err, "Failed to generate the certs of node %s", node.ID)
+		return node
+	}
+
+	node.tlsCert = cert.String()
+	node.privateKey = key.String()
+	return node
+}
+
+// GetNodeID returns the NodeID derived from the node staking cert
+// Nodes without certs have their NodeID generated by avalanchego, and only known once launched
+func (node *Node) GetNodeID() (string, error) {
+	if !node.HasCerts() {
+		return "", stacktrace.NewError("Node %s has no certs, its NodeID is only known once launched", node.ID)
+	}
+	return certs.NodeID([]byte(node.tlsCert))
+}
+
 func (node *Node) BootstrapNode(bootstrap bool) *Node {
 	node.isBootstrapNode = bootstrap
 	return node
@@ -195,12 +305,70 @@ func (node *Node) GetBootstrapNodeID() int {
 func (node *Node) String() string {
 	return fmt.Sprintf("NodeID: %s, HasCerts: %v, serviceLogLevel: %s, imageName: %s, snowQuorumSize: %d,"+
 		"snowSampleSize: %d, networkInitialTimeout: %v, isStaking: %v, isBootstrapNode: %v, connectedBTNodes: %s"+
-		"bootstrapNodeID: %d",
+		"bootstrapNodeID: %d, isByzantine: %v",
 		node.ID, node.varyCerts, node.serviceLogLevel, node.imageName, node.snowQuorumSize, node.snowSampleSize,
 		node.networkInitialTimeout, node.isStaking, node.isBootstrapNode, node.connectedBTNodes, node.bootstrapNodeID,
+		node.isByzantine,
 	)
 }
 
+// LogLevel sets the avalanchego log level of the node
+func (node *Node) LogLevel(level constants.AvalancheLogLevel) *Node {
+	node.serviceLogLevel = level
+	return node
+}
+
+func (node *Node) GetLogLevel() constants.AvalancheLogLevel {
+	return node.serviceLogLevel
+}
+
+// SnowSize overrides the Network snow sample and quorum sizes for this node
+func (node *Node) SnowSize(snowSampleSize int, snowQuorumSize int) *Node {
+	node.snowSampleSize = snowSampleSize
+	node.snowQuorumSize = snowQuorumSize
+	return node
+}
+
+// GetSnowSampleSize returns the node snow sample size, or the Network one if the node doesn't override it
+func (node *Node) GetSnowSampleSize(network *Network) int {
+	if node.snowSampleSize != 0 {
+		return node.snowSampleSize
+	}
+	return network.GetSnowSampleSize()
+}
+
+// GetSnowQuorumSize returns the node snow quorum size, or the Network one if the node doesn't override it
+func (node *Node) GetSnowQuorumSize(network *Network) int {
+	if node.snowQuorumSize != 0 {
+		return node.snowQuorumSize
+	}
+	return network.GetSnowQuorumSize()
+}
+
+// NetworkInitialTimeout sets the --network-initial-timeout of the node, avalanchego's default is used if it isn't set
+func (node *Node) NetworkInitialTimeout(timeout time.Duration) *Node {
+	node.networkInitialTimeout = timeout
+	return node
+}
+
+func (node *Node) GetNetworkInitialTimeout() time.Duration {
+	return node.networkInitialTimeout
+}
+
+// VaryCerts set to false makes a node without its own certs share the same staking cert (and NodeID)
+// with every other node of its Network that doesn't vary its certs
+// The shared cert is set when the node is added to the Network, see Network.AddNode
+func (node *Node) VaryCerts(b bool) *Node {
+	node.varyCerts = b
+	return node
+}
+
+func (node *Node) GetVaryCerts() bool {
+	return node.varyCerts
+}
+
+// BootstrapAttempts enables the bootstrap retries of the node, with at most [i] attempts
+// they're left to avalanchego's defaults if it isn't set
 func (node *Node) BootstrapAttempts(i int) *Node {
 	node.boostrapAttempts = i
 	return node
@@ -210,10 +378,163 @@ func (node *Node) GetBootstrapAttempts() int {
 	return node.boostrapAttempts
 }
 
-func (node *Node) GetStakingPort() int {
-	return 9651
+// WhitelistedSubnets sets the subnets the node will sync and be able to validate
+// avalanchego reads them at startup, see AvalancheNetwork.WhitelistSubnet for subnets created once the network runs
+func (node *Node) WhitelistedSubnets(subnetIDs ...string) *Node {
+	node.whitelistedSubnets = append(node.whitelistedSubnets, subnetIDs...)
+	return node
+}
+
+func (node *Node) GetWhitelistedSubnets() string {
+	return strings.Join(node.whitelistedSubnets, ",")
+}
+
+// RequireBootstrappedChains makes the node available only once [chains] (IDs or aliases) are bootstrapped
+// the P, X and C Chains are always required
+func (node *Node) RequireBootstrappedChains(chains ...string) *Node {
+	node.requiredChains = append(node.requiredChains, chains...)
+	return node
+}
+
+func (node *Node) GetRequiredChains() []string {
+	return node.requiredChains
+}
+
+// RequireBootstrappedSubnets makes the node available only once the blockchains of [subnetIDs] are bootstrapped
+// see AvalancheNetwork.RequireBootstrappedSubnet for subnets created once the network runs
+func (node *Node) RequireBootstrappedSubnets(subnetIDs ...string) *Node {
+	node.requiredSubnets = append(node.requiredSubnets, subnetIDs...)
+	return node
+}
+
+func (node *Node) GetRequiredSubnets() []string {
+	return node.requiredSubnets
+}
+
+// MinPeers makes the node available only once it's connected to at least [minPeers] peers
+func (node *Node) MinPeers(minPeers int) *Node {
+	node.minPeers = minPeers
+	return node
+}
+
+func (node *Node) GetMinPeers() int {
+	return node.minPeers
+}
+
+// Flag sets an extra avalanchego [flag] (e.g. --api-admin-enabled) to [value] when launching the node
+func (node *Node) Flag(flag string, value string) *Node {
+	node.flags[flag] = value
+	return node
+}
+
+func (node *Node) GetFlags() map[string]string {
+	return node.flags
+}
+
+// Config sets the node config file [key] to [value]
+func (node *Node) Config(key string, value interface{}) *Node {
+	node.config[key] = value
+	return node
+}
+
+func (node *Node) GetConfig() map[string]interface{} {
+	return node.config
+}
+
+// CChainConfig sets the C Chain (coreth) config [key] to [value], overriding the default value
+func (node *Node) CChainConfig(key string, value interface{}) *Node {
+	node.cChainConfig[key] = value
+	return node
+}
+
+func (node *Node) GetCChainConfig() map[string]interface{} {
+	return node.cChainConfig
+}
+
+// DataDir sets the directory, relative to the test volume, where the node keeps its database and its logs
+// it outlives the node container so the database is kept when the node is restarted
+func (node *Node) DataDir(dir string) *Node {
+	node.dataDir = dir
+	return node
+}
+
+func (node *Node) GetDataDir() string {
+	return node.dataDir
+}
+
+// Byzantine marks the node as adversarial, the node itself runs the same avalanchego as the honest ones
+// but the tests use it to issue conflicting and stale transactions (see the byzantine package)
+func (node *Node) Byzantine(b bool) *Node {
+	node.isByzantine = b
+	return node
+}
+
+func (node *Node) IsByzantine() bool {
+	return node.isByzantine
+}
+
+// GetStakingPort returns the staking port of the node, it fails if the --staking-port flag isn't a number
+func (node *Node) GetStakingPort() (int, error) {
+	return node.portFlag(stakingPortFlag, defaultStakingPort)
+}
+
+// GetHTTPPort returns the HTTP port of the node, it fails if the --http-port flag isn't a number
+func (node *Node) GetHTTPPort() (int, error) {
+	return node.portFlag(httpPortFlag, defaultHTTPPort)
+}
+
+// validate checks the settings of the node, using the [network] settings the node doesn't override
+func (node *Node) validate(network *Network) error {
+	snowSampleSize := node.GetSnowSampleSize(network)
+	snowQuorumSize := node.GetSnowQuorumSize(network)
+	if snowSampleSize <= 0 || snowQuorumSize <= 0 {
+		return stacktrace.NewError("Snow sample size (%d) and quorum size (%d) must be positive", snowSampleSize, snowQuorumSize)
+	}
+	if snowQuorumSize > snowSampleSize {
+		return stacktrace.NewError("Snow quorum size (%d) can't be greater than the sample size (%d)", snowQuorumSize, snowSampleSize)
+	}
+
+	if node.certErr != nil {
+		return node.certErr
+	}
+	if !constants.IsValidLogLevel(node.serviceLogLevel) {
+		return stacktrace.NewError("Unknown log level %s", node.serviceLogLevel)
+	}
+	if node.networkInitialTimeout < 0 {
+		return stacktrace.NewError("Network initial timeout (%v) can't be negative", node.networkInitialTimeout)
+	}
+	if node.minPeers < 0 {
+		return stacktrace.NewError("Min peers (%d) can't be negative", node.minPeers)
+	}
+	if node.boostrapAttempts < 0 {
+		return stacktrace.NewError("Bootstrap attempts (%d) can't be negative", node.boostrapAttempts)
+	}
+	if !node.varyCerts && !node.HasCerts() {
+		return stacktrace.NewError("Nodes that don't vary their certs must be set so before they're added to the network")
+	}
+	if node.isBootstrapNode && !node.HasCerts() {
+		return stacktrace.NewError("Bootstrap nodes need certs so their NodeID is known in advance")
+	}
+	if _, err := node.GetStakingPort(); err != nil {
+		return err
+	}
+	if _, err := node.GetHTTPPort(); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (node *Node) GetHTTPPort() int {
-	return 9650
+// portFlag returns the port set with [flag], or [defaultPort]
+// it fails if the flag isn't a number, as avalanchego would be started with it anyway
+func (node *Node) portFlag(flag string, defaultPort int) (int, error) {
+	value, ok := node.flags[flag]
+	if !ok {
+		return defaultPort, nil
+	}
+
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, stacktrace.Propagate(err, "Flag %s of node %s must be a port number, got %s", flag, node.ID, value)
+	}
+	return port, nil
 }
@@ -0,0 +1,77 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networkbuilder
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants/certs"
+)
+
+func TestNodePorts(t *testing.T) {
+	node := NewNode("node")
+	if port, err := node.GetHTTPPort(); err != nil || port != defaultHTTPPort {
+		t.Errorf("expected the default HTTP port, got %d (%v)", port, err)
+	}
+	if port, err := node.GetStakingPort(); err != nil || port != defaultStakingPort {
+		t.Errorf("expected the default staking port, got %d (%v)", port, err)
+	}
+
+	node.Flag(httpPortFlag, "9660").Flag(stakingPortFlag, "9661")
+	if port, err := node.GetHTTPPort(); err != nil || port != 9660 {
+		t.Errorf("expected the HTTP port flag, got %d (%v)", port, err)
+	}
+	if port, err := node.GetStakingPort(); err != nil || port != 9661 {
+		t.Errorf("expected the staking port flag, got %d (%v)", port, err)
+	}
+
+	network := New().SnowSize(1, 1).AddNode(node)
+	if err := network.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	node.Flag(stakingPortFlag, "staking")
+	if _, err := node.GetStakingPort(); err == nil {
+		t.Error("expected an error for a staking port flag that isn't a number")
+	}
+	if err := network.Validate(); err == nil {
+		t.Error("expected the network to be invalid with a staking port flag that isn't a number")
+	}
+}
+
+func TestSharedCerts(t *testing.T) {
+	network := New().SnowSize(1, 1).
+		AddNode(NewNode("first").VaryCerts(false)).
+		AddNode(NewNode("second").VaryCerts(false)).
+		AddNode(NewNode("varying").CertProvider(certs.NewRandomAvalancheCertProvider(true)))
+	if err := network.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	nodeID := func(node *Node) string {
+		id, err := node.GetNodeID()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return id
+	}
+	if nodeID(network.Nodes["first"]) != nodeID(network.Nodes["second"]) {
+		t.Error("expected the nodes that don't vary their certs to share their NodeID")
+	}
+	if nodeID(network.Nodes["first"]) == nodeID(network.Nodes["varying"]) {
+		t.Error("expected the node with its own certs to have its own NodeID")
+	}
+
+	otherNetwork := New().SnowSize(1, 1).AddNode(NewNode("first").VaryCerts(false))
+	if nodeID(network.Nodes["first"]) == nodeID(otherNetwork.Nodes["first"]) {
+		t.Error("expected each network to have its own shared cert")
+	}
+
+	lateNode := NewNode("late")
+	otherNetwork.AddNode(lateNode)
+	lateNode.VaryCerts(false)
+	if err := otherNetwork.Validate(); err == nil {
+		t.Error("expected an error for a node set not to vary its certs after it was added")
+	}
+}
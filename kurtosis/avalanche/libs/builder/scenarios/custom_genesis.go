@@ -0,0 +1,55 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package scenarios
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants/certs"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/formatters"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+// NewCustomGenesisNetwork creates a network [networkID] with its own genesis, validated by [numStakers]
+// bootstrap nodes with generated certs
+// The funded key is the same as the local network one, it holds [xAmount] on the X Chain
+func NewCustomGenesisNetwork(avalancheImage string, networkID uint32, numStakers int, xAmount uint64) (*networkbuilder.Network, error) {
+	fundedKey := constants.DefaultLocalNetGenesisConfig.FundedAddresses.PrivateKey
+	privateKey, err := formatters.ConvertFormattedPrivateKey(fundedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	genesis := networkbuilder.NewGenesis(networkID, fundedKey).
+		XAllocation(privateKey.PublicKey().Address(), xAmount).
+		StakedAmount(uint64(numStakers) * 2000 * units.Avax)
+
+	newNetwork := networkbuilder.New().
+		Image(avalancheImage).
+		SnowSize(numStakers, numStakers).
+		CustomGenesis(genesis)
+
+	certProvider := certs.NewRandomAvalancheCertProvider(true)
+	for i := 1; i <= numStakers; i++ {
+		node := networkbuilder.NewNode(fmt.Sprintf("bootstrapNode-%d", i)).
+			Image(avalancheImage).
+			IsStaking(true).
+			BootstrapNode(true).
+			BootstrapNodeID(i).
+			CertProvider(certProvider)
+
+		nodeID, err := node.GetNodeID()
+		if err != nil {
+			return nil, err
+		}
+
+		genesis.Staker(node)
+		newNetwork.AddNode(node)
+		newNetwork.ConnectedBTNodeIDs(nodeID)
+	}
+
+	return newNetwork.HasBootstrapNodes(true), nil
+}
@@ -0,0 +1,29 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package scenarios
+
+import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants/certs"
+)
+
+// NewDuplicateNodeIDNetwork creates the five nodes bootstrapping network plus the node [firstNodeID], and returns
+// the node [secondNodeID] using the same staking cert, hence the same NodeID
+// The second node isn't part of the network, it's meant to be launched once the first one runs
+func NewDuplicateNodeIDNetwork(avalancheImage string, firstNodeID string, secondNodeID string) (*networkbuilder.Network, *networkbuilder.Node) {
+	newNetwork := NewBootStrappingNodeNetwork(avalancheImage)
+
+	certProvider := certs.NewRandomAvalancheCertProvider(false)
+	nodes := make([]*networkbuilder.Node, 0, 2)
+	for _, nodeID := range []string{firstNodeID, secondNodeID} {
+		nodes = append(nodes, networkbuilder.NewNode(nodeID).
+			Image(avalancheImage).
+			IsStaking(true).
+			CertProvider(certProvider),
+		)
+	}
+	newNetwork.AddNode(nodes[0])
+
+	return newNetwork, nodes[1]
+}
@@ -0,0 +1,232 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package topology
+
+import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	cjson "github.com/ava-labs/avalanchego/utils/json"
+)
+
+// MinterSet lets [Threshold] of the [Minters] X Chain addresses mint a variable cap asset together
+// the addresses signing a mint must all be controlled by the minting Node user (see Node.TryCreateXAddress)
+type MinterSet struct {
+	Threshold uint32
+	Minters   []string
+}
+
+// Asset is an X Chain asset created by a Node of the Topology
+type Asset struct {
+	name    string
+	AssetID ids.ID
+	creator *Node
+	// lookupErr is set on the placeholder returned for an Asset that wasn't added, see Topology.Asset
+	lookupErr error
+	errs      *buildErrs
+}
+
+func newAsset(name string, creator *Node, errs *buildErrs) *Asset {
+	return &Asset{
+		name:    name,
+		creator: creator,
+		errs:    errs,
+	}
+}
+
+// createFixedCap issues the CreateAsset transaction of a fixed cap asset, whose whole supply goes to [holders]
+// (X Chain addresses), paid by the creator Node from its AVAX balance
+func (a *Asset) createFixedCap(symbol string, denomination byte, holders map[string]uint64) error {
+	if len(holders) == 0 {
+		return stacktrace.NewError("Fixed cap asset %s has no holders", a.name)
+	}
+	client, err := a.creator.TryGetClient()
+	if err != nil {
+		return err
+	}
+
+	var initialHolders []*avm.Holder
+	for address, amount := range holders {
+		initialHolders = append(initialHolders, &avm.Holder{
+			Amount:  cjson.Uint64(amount),
+			Address: address,
+		})
+	}
+
+	assetID, err := client.XChainAPI().CreateFixedCapAsset(
+		a.creator.UserPass,
+		nil, // from addrs
+		"",  // change addr
+		a.name,
+		symbol,
+		denomination,
+		initialHolders,
+	)
+	if err != nil {
+		client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to create fixed cap asset %s", a.name)
+	}
+	return a.awaitCreation(client, assetID)
+}
+
+// createVariableCap issues the CreateAsset transaction of a variable cap asset, minted by the [minterSets]
+// paid by the creator Node from its AVAX balance
+func (a *Asset) createVariableCap(symbol string, denomination byte, minterSets []MinterSet) error {
+	if len(minterSets) == 0 {
+		return stacktrace.NewError("Variable cap asset %s has no minters", a.name)
+	}
+	client, err := a.creator.TryGetClient()
+	if err != nil {
+		return err
+	}
+
+	var owners []avm.Owners
+	for _, minterSet := range minterSets {
+		if minterSet.Threshold == 0 || int(minterSet.Threshold) > len(minterSet.Minters) {
+			return stacktrace.NewError("Asset %s minter set threshold %d is invalid for %d minters",
+				a.name, minterSet.Threshold, len(minterSet.Minters))
+		}
+		owners = append(owners, avm.Owners{
+			Threshold: cjson.Uint32(minterSet.Threshold),
+			Minters:   minterSet.Minters,
+		})
+	}
+
+	assetID, err := client.XChainAPI().CreateVariableCapAsset(
+		a.creator.UserPass,
+		nil, // from addrs
+		"",  // change addr
+		a.name,
+		symbol,
+		denomination,
+		owners,
+	)
+	if err != nil {
+		client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to create variable cap asset %s", a.name)
+	}
+	return a.awaitCreation(client, assetID)
+}
+
+func (a *Asset) awaitCreation(client *avalanchegoclient.Client, assetID ids.ID) error {
+	// the ID of the asset is the ID of the tx that created it
+	err := chainhelper.XChain().AwaitTransactionAcceptance(client, assetID, constants.TimeoutDuration)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to accept CreateAsset tx: %s", assetID)
+	}
+
+	a.AssetID = assetID
+	logrus.Infof("Created Asset: %s AssetID: %s", a.name, a.AssetID)
+	return nil
+}
+
+// Mint mints [amount] of the Asset to the X Chain address of [to], signed by the minter addresses of [minter]
+// Failures are recorded and can be retrieved with Err
+func (a *Asset) Mint(minter *Node, to *Node, amount uint64) *Asset {
+	if a.errs.Errored() {
+		return a
+	}
+	a.errs.Add(a.TryMint(minter, to.XAddress, amount))
+	return a
+}
+
+// TryMint mints [amount] of the Asset to the X Chain [address]
+// it fails if the [minter] Node user doesn't control enough addresses of a minter set to reach its threshold
+func (a *Asset) TryMint(minter *Node, address string, amount uint64) error {
+	if a.lookupErr != nil {
+		return a.lookupErr
+	}
+	client, err := minter.TryGetClient()
+	if err != nil {
+		return err
+	}
+	txID, err := client.XChainAPI().Mint(
+		minter.UserPass,
+		nil, // from addrs
+		"",  // change addr
+		amount,
+		a.AssetID.String(),
+		address,
+	)
+	if err != nil {
+		client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to mint %d of asset %s", amount, a.name)
+	}
+
+	err = chainhelper.XChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to accept Mint tx: %s", txID)
+	}
+	logrus.Infof("Minted %d of Asset: %s to Address: %s", amount, a.name, address)
+	return nil
+}
+
+// Transfer sends [amount] of the Asset from the [from] Node to the X Chain address of the [to] Node
+// Failures are recorded and can be retrieved with Err
+func (a *Asset) Transfer(from *Node, to *Node, amount uint64) *Asset {
+	if a.errs.Errored() {
+		return a
+	}
+	a.errs.Add(a.TryTransfer(from, to.XAddress, amount))
+	return a
+}
+
+// TryTransfer sends [amount] of the Asset from the [from] Node to the X Chain [address]
+// the [from] Node pays the transaction fee from its AVAX balance
+func (a *Asset) TryTransfer(from *Node, address string, amount uint64) error {
+	if a.lookupErr != nil {
+		return a.lookupErr
+	}
+	client, err := from.TryGetClient()
+	if err != nil {
+		return err
+	}
+	txID, err := client.XChainAPI().Send(
+		from.UserPass,
+		nil, // from addrs
+		"",  // change addr
+		amount,
+		a.AssetID.String(),
+		address,
+		"", // memo
+	)
+	if err != nil {
+		client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to send %d of asset %s to %s", amount, a.name, address)
+	}
+
+	err = chainhelper.XChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to accept Send tx: %s", txID)
+	}
+	logrus.Infof("Transferred %d of Asset: %s to Address: %s", amount, a.name, address)
+	return nil
+}
+
+// CheckBalance verifies the X Chain address of [node] holds [expectedAmount] of the Asset
+// The balance is polled until it matches, [node] may not have accepted the last transactions issued through another node
+// Failures are recorded and can be retrieved with Err
+func (a *Asset) CheckBalance(node *Node, expectedAmount uint64) *Asset {
+	if a.errs.Errored() {
+		return a
+	}
+	client, err := node.TryGetClient()
+	if err != nil {
+		a.errs.Add(err)
+		return a
+	}
+	expectedAmounts := map[string]uint64{a.AssetID.String(): expectedAmount}
+	a.errs.Add(chainhelper.XChain().AwaitBalances(client, node.XAddress, expectedAmounts, constants.TimeoutDuration))
+	return a
+}
+
+// Err returns the first error recorded while building the Topology this Asset belongs to
+func (a *Asset) Err() error {
+	return a.errs.Err()
+}
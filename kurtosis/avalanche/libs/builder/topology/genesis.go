@@ -10,6 +10,7 @@ import (
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/vms/avm"
@@ -22,36 +23,58 @@ import (
 )
 
 // Genesis is a single attribute of the Topology (only one Genesis) that holds the Genesis data
+// its client is looked up in the network on every call, like the one of a Node
 type Genesis struct {
 	id       string
-	client   *avalanchegoclient.Client
+	network  *networksavalanche.AvalancheNetwork
 	Address  string
 	userPass api.UserPass
+	config   *constants.NetworkGenesisConfig
+	// lookupErr is set on the placeholder returned when no Genesis was added, see Topology.Genesis
+	lookupErr error
+	errs      *buildErrs
 }
 
-func newGenesis(id string, userName string, password string, client *avalanchegoclient.Client) *Genesis {
+func newGenesis(id string, userName string, password string, network *networksavalanche.AvalancheNetwork, config *constants.NetworkGenesisConfig, errs *buildErrs) *Genesis {
 	return &Genesis{
 		id: id,
 		userPass: api.UserPass{
 			Username: userName,
 			Password: password,
 		},
-		client:  client,
+		network: network,
+		config:  config,
+		errs:    errs,
 	}
 }
 
-// ImportGenesisFunds fetches the default funded funds and imports them
+// getClient returns the client of the running genesis node, or the error of the Genesis lookup
+func (g *Genesis) getClient() (*avalanchegoclient.Client, error) {
+	if g.lookupErr != nil {
+		return nil, g.lookupErr
+	}
+	client, err := g.network.GetNodeClient(g.id)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Genesis node %s is not running", g.id)
+	}
+	return client, nil
+}
+
+// ImportGenesisFunds imports the funded key of the network genesis
 func (g *Genesis) ImportGenesisFunds() error {
-	var err error
+	client, err := g.getClient()
+	if err != nil {
+		return err
+	}
 
-	keystore := g.client.KeystoreAPI()
+	keystore := client.KeystoreAPI()
 	if _, err = keystore.CreateUser(g.userPass); err != nil {
 		return stacktrace.Propagate(err, "Failed to take create genesis user account.")
 	}
 
-	g.Address, err = g.client.XChainAPI().ImportKey(
+	g.Address, err = client.XChainAPI().ImportKey(
 		g.userPass,
-		constants.DefaultLocalNetGenesisConfig.FundedAddresses.PrivateKey)
+		g.config.FundedAddresses.PrivateKey)
 	if err != nil {
 		return stacktrace.Propagate(err, "Failed to take control of genesis account.")
 	}
@@ -60,9 +83,23 @@ func (g *Genesis) ImportGenesisFunds() error {
 }
 
 // FundXChainAddresses funds the genesis funds into an address on the XChain
+// Failures are recorded and can be retrieved with Err
 func (g *Genesis) FundXChainAddresses(addresses []string, amount uint64) *Genesis {
+	if g.errs.Errored() {
+		return g
+	}
+	g.errs.Add(g.TryFundXChainAddresses(addresses, amount))
+	return g
+}
+
+// TryFundXChainAddresses is the error returning version of FundXChainAddresses
+func (g *Genesis) TryFundXChainAddresses(addresses []string, amount uint64) error {
+	client, err := g.getClient()
+	if err != nil {
+		return err
+	}
 	for _, address := range addresses {
-		txID, err := g.client.XChainAPI().Send(
+		txID, err := client.XChainAPI().Send(
 			g.userPass,
 			nil,    // from addrs
 			"",     // change addr
@@ -72,31 +109,44 @@ func (g *Genesis) FundXChainAddresses(addresses []string, amount uint64) *Genesi
 			"",
 		)
 		if err != nil {
-			panic(stacktrace.Propagate(err, "Failed to fund addresses with genesis funds."))
-			return g
+			client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+			return stacktrace.Propagate(err, "Failed to fund addresses with genesis funds.")
 		}
 
 		// wait for the tx to go through
-		err = chainhelper.XChain().AwaitTransactionAcceptance(g.client, txID, constants.TimeoutDuration)
+		err = chainhelper.XChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration)
 		if err != nil {
-			panic(stacktrace.Propagate(err, "Timed out waiting for transaction to be accepted on the XChain"))
-			return g
+			return stacktrace.Propagate(err, "Timed out waiting for transaction to be accepted on the XChain")
 		}
 
 		// verify the balance
-		err = chainhelper.XChain().CheckBalance(g.client, address, "AVAX", amount)
+		err = chainhelper.XChain().CheckBalance(client, address, "AVAX", amount)
 		if err != nil {
-			panic(stacktrace.Propagate(err, "Failed to validate fund on the XChain"))
-			return g
+			return stacktrace.Propagate(err, "Failed to validate fund on the XChain")
 		}
 
 		logrus.Infof("Funded X Chain Address: %s with %d.", address, amount)
 	}
 
-	return g
+	return nil
 }
 
+// MultipleFundXChainAddresses funds each address with [times] utxos of [amount], one transaction per address
+// Failures are recorded and can be retrieved with Err
 func (g *Genesis) MultipleFundXChainAddresses(addresses []string, amount uint64, times int) *Genesis {
+	if g.errs.Errored() {
+		return g
+	}
+	g.errs.Add(g.TryMultipleFundXChainAddresses(addresses, amount, times))
+	return g
+}
+
+// TryMultipleFundXChainAddresses is the error returning version of MultipleFundXChainAddresses
+func (g *Genesis) TryMultipleFundXChainAddresses(addresses []string, amount uint64, times int) error {
+	client, err := g.getClient()
+	if err != nil {
+		return err
+	}
 	txIDs := make([]ids.ID, len(addresses))
 	for i, address := range addresses {
 		// create the multiple outputs
@@ -110,18 +160,18 @@ func (g *Genesis) MultipleFundXChainAddresses(addresses []string, amount uint64,
 		}
 
 		// send it
-		txID, err := g.client.XChainAPI().SendMultiple(g.userPass, nil, "", sendOutputs, "")
+		txID, err := client.XChainAPI().SendMultiple(g.userPass, nil, "", sendOutputs, "")
 		if err != nil {
-			panic(stacktrace.Propagate(err, "Failed to send transaction with %d outputs", len(sendOutputs)))
-			return g
+			client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+			return stacktrace.Propagate(err, "Failed to send transaction with %d outputs", len(sendOutputs))
 		}
 
 		logrus.Infof("Sent transaction %s with %d outputs", txID, len(sendOutputs))
 
 		// wait for the transactions to be accepted
-		err = chainhelper.XChain().AwaitTransactionAcceptance(g.client, txID, constants.TimeoutDuration)
+		err = chainhelper.XChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration)
 		if err != nil {
-			panic(stacktrace.Propagate(err, "Failed to wait transaction accepted for address %s", addresses[i]))
+			return stacktrace.Propagate(err, "Failed to wait transaction accepted for address %s", addresses[i])
 		}
 		logrus.Infof("Transaction: %v accepted for address %s with %d utxos", txID.String(), address, len(sendOutputs))
 
@@ -135,9 +185,10 @@ func (g *Genesis) MultipleFundXChainAddresses(addresses []string, amount uint64,
 	// check the balance
 	logrus.Infof("Expected amount on each address of : %v", amount*uint64(times))
 	for _, address := range addresses {
+		address := address
 		errG.Go(func() error {
 			// verify the balance
-			err := chainhelper.XChain().CheckBalance(g.client, address, "AVAX", amount*uint64(times))
+			err := chainhelper.XChain().CheckBalance(client, address, "AVAX", amount*uint64(times))
 			if err != nil {
 				return err
 			}
@@ -145,16 +196,31 @@ func (g *Genesis) MultipleFundXChainAddresses(addresses []string, amount uint64,
 			return nil
 		})
 	}
-	err := errG.Wait()
+	err = errG.Wait()
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed to check funds in addresses"))
+		return stacktrace.Propagate(err, "Failed to check funds in addresses")
 	}
 
 	logrus.Infof("Funded X Chain Addresses: %d with %d in %v seconds.", len(addresses), amount, time.Since(startTime).Seconds())
-	return g
+	return nil
 }
 
+// MultipleFundXChainAddresses2 funds each address with [times] utxos of [amount] in a single transaction
+// Failures are recorded and can be retrieved with Err
 func (g *Genesis) MultipleFundXChainAddresses2(addresses []string, amount uint64, times int) *Genesis {
+	if g.errs.Errored() {
+		return g
+	}
+	g.errs.Add(g.TryMultipleFundXChainAddresses2(addresses, amount, times))
+	return g
+}
+
+// TryMultipleFundXChainAddresses2 is the error returning version of MultipleFundXChainAddresses2
+func (g *Genesis) TryMultipleFundXChainAddresses2(addresses []string, amount uint64, times int) error {
+	client, err := g.getClient()
+	if err != nil {
+		return err
+	}
 	txIDs := make([]ids.ID, times)
 	// create the multiple outputs
 	sendOutputs := make([]avm.SendOutput, 0, times)
@@ -169,18 +235,18 @@ func (g *Genesis) MultipleFundXChainAddresses2(addresses []string, amount uint64
 	}
 
 	// send it
-	txID, err := g.client.XChainAPI().SendMultiple(g.userPass, nil, "", sendOutputs, "")
+	txID, err := client.XChainAPI().SendMultiple(g.userPass, nil, "", sendOutputs, "")
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed to send transaction with %d outputs", len(sendOutputs)))
-		return g
+		client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to send transaction with %d outputs", len(sendOutputs))
 	}
 
 	logrus.Infof("Sent 1 transaction %s with %d outputs", txID, len(sendOutputs))
 
 	// wait for the transactions to be accepted
-	err = chainhelper.XChain().AwaitTransactionAcceptance(g.client, txID, constants.TimeoutDuration)
+	err = chainhelper.XChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed to wait transaction accepted"))
+		return stacktrace.Propagate(err, "Failed to wait transaction accepted")
 	}
 	logrus.Infof("Transaction: %v accepted for addresses %v with %d utxos", txID, addresses, len(sendOutputs))
 
@@ -191,9 +257,10 @@ func (g *Genesis) MultipleFundXChainAddresses2(addresses []string, amount uint64
 	// check the balance
 	logrus.Infof("Expected amount on each address of : %v", amount*uint64(times))
 	for _, address := range addresses {
+		address := address
 		errG.Go(func() error {
 			// verify the balance
-			err := chainhelper.XChain().CheckBalance(g.client, address, "AVAX", amount*uint64(times))
+			err := chainhelper.XChain().CheckBalance(client, address, "AVAX", amount*uint64(times))
 			if err != nil {
 				return err
 			}
@@ -203,55 +270,91 @@ func (g *Genesis) MultipleFundXChainAddresses2(addresses []string, amount uint64
 	}
 	err = errG.Wait()
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed to check funds in addresses"))
+		return stacktrace.Propagate(err, "Failed to check funds in addresses")
 	}
 
 	logrus.Infof("Funded X Chain Addresses: %d with %d in %v seconds.", len(addresses), amount, time.Since(startTime).Seconds())
+	return nil
+}
+
+// FundCChainAddresses exports [amount] from the genesis XChain address and imports it into each of [addrs]
+// Failures are recorded and can be retrieved with Err
+func (g *Genesis) FundCChainAddresses(addrs []common.Address, amount uint64) *Genesis {
+	if g.errs.Errored() {
+		return g
+	}
+	g.errs.Add(g.TryFundCChainAddresses(addrs, amount))
 	return g
 }
 
-func (g *Genesis) FundCChainAddresses(addrs []common.Address, amount uint64) {
+// TryFundCChainAddresses is the error returning version of FundCChainAddresses
+func (g *Genesis) TryFundCChainAddresses(addrs []common.Address, amount uint64) error {
+	client, err := g.getClient()
+	if err != nil {
+		return err
+	}
 	logrus.Infof("Using address : %v", g.Address)
-	_, err := g.client.CChainAPI().ImportKey(
+	_, err = client.CChainAPI().ImportKey(
 		g.userPass,
-		constants.DefaultLocalNetGenesisConfig.FundedAddresses.PrivateKey)
+		g.config.FundedAddresses.PrivateKey)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "unable to fund cchain"))
+		return stacktrace.Propagate(err, "unable to fund cchain")
 	}
 
 	for _, addr := range addrs {
 		cChainBech32 := fmt.Sprintf("C%s", g.Address[1:])
-		txID, err := g.client.XChainAPI().ExportAVAX(g.userPass, nil, "", amount, cChainBech32)
+		txID, err := client.XChainAPI().ExportAVAX(g.userPass, nil, "", amount, cChainBech32)
 		if err != nil {
-			panic(stacktrace.Propagate(err, "Failed to export AVAX to C-Chain"))
+			client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+			return stacktrace.Propagate(err, "Failed to export AVAX to C-Chain")
 		}
-		err = chainhelper.XChain().AwaitTransactionAcceptance(g.client, txID, constants.TimeoutDuration)
+		err = chainhelper.XChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration)
 		if err != nil {
-			panic(stacktrace.Propagate(err, "Timed out waiting to export AVAX to C-Chain"))
-
+			return stacktrace.Propagate(err, "Timed out waiting to export AVAX to C-Chain")
 		}
 
-		txID, err = g.client.CChainAPI().Import(g.userPass, addr.Hex(), "X")
+		txID, err = client.CChainAPI().Import(g.userPass, addr.Hex(), "X")
 		if err != nil {
-			panic(stacktrace.Propagate(err, "Failed to import AVAX to C-Chain"))
+			client.RecordTx(avalanchegoclient.CChain, "", avalanchegoclient.TxIssueFailed)
+			return stacktrace.Propagate(err, "Failed to import AVAX to C-Chain")
 		}
 
-		err = chainhelper.CChain().AwaitTransactionAcceptance(g.client, txID, constants.TimeoutDuration)
+		err = chainhelper.CChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration)
 		if err != nil {
-			panic(stacktrace.Propagate(err, "Timed out waiting to import AVAX to C-Chain"))
-
+			return stacktrace.Propagate(err, "Timed out waiting to import AVAX to C-Chain")
 		}
 	}
+	return nil
 }
 
-func (g *Genesis) MoveBalanceToCChain(addr common.Address, txFee uint64) {
-	balance, err := g.client.XChainAPI().GetBalance(g.Address, constants.AvaxAssetID.String(), true)
+// MoveBalanceToCChain moves half of the genesis XChain balance into [addr]
+// Failures are recorded and can be retrieved with Err
+func (g *Genesis) MoveBalanceToCChain(addr common.Address, txFee uint64) *Genesis {
+	if g.errs.Errored() {
+		return g
+	}
+	g.errs.Add(g.TryMoveBalanceToCChain(addr, txFee))
+	return g
+}
+
+// TryMoveBalanceToCChain is the error returning version of MoveBalanceToCChain
+func (g *Genesis) TryMoveBalanceToCChain(addr common.Address, txFee uint64) error {
+	client, err := g.getClient()
+	if err != nil {
+		return err
+	}
+	balance, err := client.XChainAPI().GetBalance(g.Address, g.config.ChainIDs.AvaxAssetID.String(), true)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Unable to fetch balance from the genesis X chain address"))
+		return stacktrace.Propagate(err, "Unable to fetch balance from the genesis X chain address")
 	}
 
 	sendableBalance := (uint64(balance.Balance) - txFee) / 2
 	logrus.Infof("Balance: %v, txFee: %v,Sendable : %v", uint64(balance.Balance), txFee, sendableBalance)
 
-	g.FundCChainAddresses([]common.Address{addr}, sendableBalance)
+	return g.TryFundCChainAddresses([]common.Address{addr}, sendableBalance)
+}
+
+// Err returns the first error recorded while building the Topology this Genesis belongs to
+func (g *Genesis) Err() error {
+	return g.errs.Err()
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/palantir/stacktrace"
@@ -16,20 +17,29 @@ import (
 )
 
 // Node defines the Node in the Topology
+// Its client is looked up in the network on every call, so the Node keeps working after it's restarted
+// (see AvalancheNetwork.RestartNode), with a new container and IP address but the same NodeID
 type Node struct {
-	id        string
-	UserPass  api.UserPass
-	PAddress  string
-	XAddress  string
-	client    *avalanchegoclient.Client
-	NodeID    string
-	ipAddress string
+	id       string
+	UserPass api.UserPass
+	PAddress string
+	XAddress string
+	NodeID   string
+	network  *networksavalanche.AvalancheNetwork
+	chainIDs constants.ChainIDs
+	// lookupErr is set on the placeholder returned for a Node that wasn't added, see Topology.Node
+	lookupErr error
+	errs      *buildErrs
 }
 
-func newNode(id string, userName string, password string, ipAddress string, client *avalanchegoclient.Client) *Node {
+func newNode(id string, userName string, password string, network *networksavalanche.AvalancheNetwork, chainIDs constants.ChainIDs, errs *buildErrs) (*Node, error) {
+	client, err := network.GetNodeClient(id)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to fetch the Avalanche client")
+	}
 	nodeID, err := client.InfoAPI().GetNodeID()
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Could not get node ID."))
+		return nil, stacktrace.Propagate(err, "Could not get node ID.")
 	}
 
 	return &Node{
@@ -38,36 +48,80 @@ func newNode(id string, userName string, password string, ipAddress string, clie
 			Username: userName,
 			Password: password,
 		},
-		NodeID:    nodeID,
-		client:    client,
-		ipAddress: ipAddress,
-	}
+		NodeID:   nodeID,
+		network:  network,
+		chainIDs: chainIDs,
+		errs:     errs,
+	}, nil
 }
 
 // CreateAddress creates user and both XChain and PChain addresses for the Node
+// Failures are recorded and can be retrieved with Err
 func (n *Node) CreateAddress() *Node {
-	keystore := n.client.KeystoreAPI()
+	if n.errs.Errored() {
+		return n
+	}
+	n.errs.Add(n.createAddress())
+	return n
+}
+
+func (n *Node) createAddress() error {
+	client, err := n.TryGetClient()
+	if err != nil {
+		return err
+	}
+
+	keystore := client.KeystoreAPI()
 	if _, err := keystore.CreateUser(n.UserPass); err != nil {
-		panic(stacktrace.Propagate(err, "Could not create user for node."))
+		return stacktrace.Propagate(err, "Could not create user for node.")
 	}
 
-	xAddress, err := n.client.XChainAPI().CreateAddress(n.UserPass)
+	xAddress, err := client.XChainAPI().CreateAddress(n.UserPass)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Could not create user address in the XChainAPI."))
+		return stacktrace.Propagate(err, "Could not create user address in the XChainAPI.")
 	}
 	n.XAddress = xAddress
 
-	pAddress, err := n.client.PChainAPI().CreateAddress(n.UserPass)
+	pAddress, err := client.PChainAPI().CreateAddress(n.UserPass)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Could not create user address in the PChainAPI."))
+		return stacktrace.Propagate(err, "Could not create user address in the PChainAPI.")
 	}
 	n.PAddress = pAddress
-	return n
+	return nil
+}
+
+// TryCreateXAddress creates another X Chain address controlled by the Node user and returns it
+// e.g. so the Node alone can reach the threshold of a multisig
+func (n *Node) TryCreateXAddress() (string, error) {
+	client, err := n.TryGetClient()
+	if err != nil {
+		return "", err
+	}
+	xAddress, err := client.XChainAPI().CreateAddress(n.UserPass)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Could not create user address in the XChainAPI.")
+	}
+	return xAddress, nil
 }
 
 // GetClient returns the RPC API client to access the nodes VMS
+// It's nil if the Node isn't running, or for the placeholder of a Node that wasn't added (see TryGetClient)
 func (n *Node) GetClient() *avalanchegoclient.Client {
-	return n.client
+	client, _ := n.TryGetClient()
+	return client
+}
+
+// TryGetClient returns the RPC API client of the running node, a new one once the node was restarted
+// it fails if the node is stopped, or with the error of the Node lookup if the Node wasn't added
+func (n *Node) TryGetClient() (*avalanchegoclient.Client, error) {
+	if n.lookupErr != nil {
+		return nil, n.lookupErr
+	}
+	client, err := n.network.GetNodeClient(n.id)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Node %s is not running", n.id)
+	}
+	return client, nil
 }
 
 // BecomeValidator is a multi step methods that does the following
@@ -77,9 +131,23 @@ func (n *Node) GetClient() *avalanchegoclient.Client {
 // - adds nodeID as a validator - waits Tx acceptance in the PChain
 // - waits until the validation period begins
 //
+// Failures are recorded and can be retrieved with Err
 func (n *Node) BecomeValidator(genesisAmount uint64, seedAmount uint64, stakeAmount uint64, txFee uint64) *Node {
+	if n.errs.Errored() {
+		return n
+	}
+	n.errs.Add(n.TryBecomeValidator(genesisAmount, seedAmount, stakeAmount, txFee))
+	return n
+}
+
+// TryBecomeValidator is the error returning version of BecomeValidator
+func (n *Node) TryBecomeValidator(genesisAmount uint64, seedAmount uint64, stakeAmount uint64, txFee uint64) error {
+	client, err := n.TryGetClient()
+	if err != nil {
+		return err
+	}
 	// exports AVAX from the X Chain
-	exportTxID, err := n.client.XChainAPI().ExportAVAX(
+	exportTxID, err := client.XChainAPI().ExportAVAX(
 		n.UserPass,
 		nil,              // from addrs
 		"",               // change addr
@@ -87,56 +155,52 @@ func (n *Node) BecomeValidator(genesisAmount uint64, seedAmount uint64, stakeAmo
 		n.PAddress,
 	)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed to export AVAX to pchainAddress %s", n.PAddress))
-		return n
+		client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to export AVAX to pchainAddress %s", n.PAddress)
 	}
 
 	// waits Tx acceptance in the XChain
-	err = chainhelper.XChain().AwaitTransactionAcceptance(n.client, exportTxID, 120*time.Second)
+	err = chainhelper.XChain().AwaitTransactionAcceptance(client, exportTxID, 120*time.Second)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed to export AVAX from XChain Address %s", n.XAddress))
-		return n
+		return stacktrace.Propagate(err, "Failed to export AVAX from XChain Address %s", n.XAddress)
 	}
 
 	// imports the amount to the P Chain
-	importTxID, err := n.client.PChainAPI().ImportAVAX( // receivedAmount = (sent - txFee)
+	importTxID, err := client.PChainAPI().ImportAVAX( // receivedAmount = (sent - txFee)
 		n.UserPass,
 		nil, // from addrs
 		"",  // change addr
 		n.PAddress,
-		constants.XChainID.String(),
+		n.chainIDs.XChainID.String(),
 	)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed import AVAX to PChain Address %s", n.PAddress))
-		return n
+		client.RecordTx(avalanchegoclient.PChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed import AVAX to PChain Address %s", n.PAddress)
 	}
 
 	// waits Tx acceptance in the PChain
-	err = chainhelper.PChain().AwaitTransactionAcceptance(n.client, importTxID, constants.TimeoutDuration)
+	err = chainhelper.PChain().AwaitTransactionAcceptance(client, importTxID, constants.TimeoutDuration)
 	if err != nil {
-		panic(err)
-		return n
+		return stacktrace.Propagate(err, "Failed to import AVAX to PChain Address %s", n.PAddress)
 	}
 
 	// verify the PChain balance of seedAmount on the PChain (which should have been at 0)
-	err = chainhelper.PChain().CheckBalance(n.client, n.PAddress, seedAmount) // balance = seedAmount = transferred + txFee
+	err = chainhelper.PChain().CheckBalance(client, n.PAddress, seedAmount) // balance = seedAmount = transferred + txFee
 	if err != nil {
-		panic(stacktrace.Propagate(err, "expected balance of seedAmount the stakeAmount was moved to XChain"))
-		return n
+		return stacktrace.Propagate(err, "expected balance of seedAmount the stakeAmount was moved to XChain")
 	}
 
 	// verify the XChain balance of (seedAmount - stakeAmount - 2*txFee) the stake was moved to PChain
-	err = chainhelper.XChain().CheckBalance(n.client, n.XAddress, "AVAX", genesisAmount-seedAmount-2*txFee)
+	err = chainhelper.XChain().CheckBalance(client, n.XAddress, "AVAX", genesisAmount-seedAmount-2*txFee)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "expected balance of (seedAmount - stakeAmount - 2*txFee) the stake was moved to XChain"))
-		return n
+		return stacktrace.Propagate(err, "expected balance of (seedAmount - stakeAmount - 2*txFee) the stake was moved to XChain")
 	}
 
 	// add nodeID as a validator
 	stakingStartTime := time.Now().Add(20 * time.Second)
 	startTime := uint64(stakingStartTime.Unix())
 	endTime := uint64(stakingStartTime.Add(72 * time.Hour).Unix())
-	addStakerTxID, err := n.client.PChainAPI().AddValidator(
+	addStakerTxID, err := client.PChainAPI().AddValidator(
 		n.UserPass,
 		nil,
 		"",
@@ -148,25 +212,23 @@ func (n *Node) BecomeValidator(genesisAmount uint64, seedAmount uint64, stakeAmo
 		float32(2),
 	)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed to add validator to primary network %s", n.id))
-		return n
+		client.RecordTx(avalanchegoclient.PChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to add validator to primary network %s", n.id)
 	}
 
 	// waits Tx acceptance in the PChain
-	err = chainhelper.PChain().AwaitTransactionAcceptance(n.client, addStakerTxID, constants.TimeoutDuration)
+	err = chainhelper.PChain().AwaitTransactionAcceptance(client, addStakerTxID, constants.TimeoutDuration)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "transaction not accepted"))
-		return n
+		return stacktrace.Propagate(err, "transaction not accepted")
 	}
 
 	// waits until the validation period begins
 	time.Sleep(time.Until(stakingStartTime) + 3*time.Second)
 
 	// verifies if the node is a current validator
-	currentStakers, err := n.client.PChainAPI().GetCurrentValidators(ids.Empty)
+	currentStakers, err := client.PChainAPI().GetCurrentValidators(ids.Empty)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Could not get current stakers."))
-		return n
+		return stacktrace.Propagate(err, "Could not get current stakers.")
 	}
 
 	found := false
@@ -179,19 +241,18 @@ func (n *Node) BecomeValidator(genesisAmount uint64, seedAmount uint64, stakeAmo
 	}
 
 	if !found {
-		panic(stacktrace.NewError("Node: %s not found in the stakers %v", n.NodeID, currentStakers))
-		return n
+		return stacktrace.NewError("Node: %s not found in the stakers %v", n.NodeID, currentStakers)
 	}
 
 	// verifies the balance of the staker in the PChain - should be the seedAmmount - stakedAmount
-	err = chainhelper.PChain().CheckBalance(n.client, n.PAddress, seedAmount-stakeAmount)
+	err = chainhelper.PChain().CheckBalance(client, n.PAddress, seedAmount-stakeAmount)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Error checking the PChain balance."))
+		return stacktrace.Propagate(err, "Error checking the PChain balance.")
 	}
 
 	logrus.Infof("Verified the staker was added to current validators and has the expected P Chain balance.")
 
-	return n
+	return nil
 }
 
 // BecomeDelegator is a multi step methods that does the following
@@ -201,10 +262,23 @@ func (n *Node) BecomeValidator(genesisAmount uint64, seedAmount uint64, stakeAmo
 // - adds nodeID as a delegator - waits Tx acceptance in the PChain
 // - waits until the validation period begins
 //
+// Failures are recorded and can be retrieved with Err
 func (n *Node) BecomeDelegator(genesisAmount uint64, seedAmount uint64, delegatorAmount uint64, txFee uint64, stakerNodeID string) *Node {
+	if n.errs.Errored() {
+		return n
+	}
+	n.errs.Add(n.TryBecomeDelegator(genesisAmount, seedAmount, delegatorAmount, txFee, stakerNodeID))
+	return n
+}
 
+// TryBecomeDelegator is the error returning version of BecomeDelegator
+func (n *Node) TryBecomeDelegator(genesisAmount uint64, seedAmount uint64, delegatorAmount uint64, txFee uint64, stakerNodeID string) error {
+	client, err := n.TryGetClient()
+	if err != nil {
+		return err
+	}
 	// exports AVAX from the X Chain
-	exportTxID, err := n.client.XChainAPI().ExportAVAX(
+	exportTxID, err := client.XChainAPI().ExportAVAX(
 		n.UserPass,
 		nil, // from addrs
 		"",  // change addr
@@ -212,55 +286,51 @@ func (n *Node) BecomeDelegator(genesisAmount uint64, seedAmount uint64, delegato
 		n.PAddress,
 	)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed to export AVAX to pchainAddress %s", n.PAddress))
-		return n
+		client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to export AVAX to pchainAddress %s", n.PAddress)
 	}
 
 	// waits Tx acceptance in the XChain
-	err = chainhelper.XChain().AwaitTransactionAcceptance(n.client, exportTxID, constants.TimeoutDuration)
+	err = chainhelper.XChain().AwaitTransactionAcceptance(client, exportTxID, constants.TimeoutDuration)
 	if err != nil {
-		panic(err)
-		return n
+		return stacktrace.Propagate(err, "Failed to export AVAX from XChain Address %s", n.XAddress)
 	}
 
 	// imports the amount to the P Chain
-	importTxID, err := n.client.PChainAPI().ImportAVAX(
+	importTxID, err := client.PChainAPI().ImportAVAX(
 		n.UserPass,
 		nil, // from addrs
 		"",  // change addr
 		n.PAddress,
-		constants.XChainID.String(),
+		n.chainIDs.XChainID.String(),
 	)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed import AVAX to pchainAddress %s", n.PAddress))
-		return n
+		client.RecordTx(avalanchegoclient.PChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed import AVAX to pchainAddress %s", n.PAddress)
 	}
 
 	// waits Tx acceptance in the PChain
-	err = chainhelper.PChain().AwaitTransactionAcceptance(n.client, importTxID, constants.TimeoutDuration)
+	err = chainhelper.PChain().AwaitTransactionAcceptance(client, importTxID, constants.TimeoutDuration)
 	if err != nil {
-		panic(err)
-		return n
+		return stacktrace.Propagate(err, "Failed to import AVAX to PChain Address %s", n.PAddress)
 	}
 
 	// verify the PChain balance (seedAmount+txFee-txFee)
-	err = chainhelper.PChain().CheckBalance(n.client, n.PAddress, seedAmount)
+	err = chainhelper.PChain().CheckBalance(client, n.PAddress, seedAmount)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "expected balance of seedAmount exists in the PChain"))
-		return n
+		return stacktrace.Propagate(err, "expected balance of seedAmount exists in the PChain")
 	}
 
 	// verify the XChain balance of genesisAmount - seedAmount - txFee - txFee (import PChain)
-	err = chainhelper.XChain().CheckBalance(n.client, n.XAddress, "AVAX", genesisAmount-seedAmount-2*txFee)
+	err = chainhelper.XChain().CheckBalance(client, n.XAddress, "AVAX", genesisAmount-seedAmount-2*txFee)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "expected balance XChain balance of genesisAmount-seedAmount-txFee"))
-		return n
+		return stacktrace.Propagate(err, "expected balance XChain balance of genesisAmount-seedAmount-txFee")
 	}
 
 	delegatorStartTime := time.Now().Add(20 * time.Second)
 	startTime := uint64(delegatorStartTime.Unix())
 	endTime := uint64(delegatorStartTime.Add(36 * time.Hour).Unix())
-	addDelegatorTxID, err := n.client.PChainAPI().AddDelegator(
+	addDelegatorTxID, err := client.PChainAPI().AddDelegator(
 		n.UserPass,
 		nil, // from addrs
 		"",  // change addr
@@ -271,30 +341,41 @@ func (n *Node) BecomeDelegator(genesisAmount uint64, seedAmount uint64, delegato
 		endTime,
 	)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed to add delegator %s", n.PAddress))
-		return n
+		client.RecordTx(avalanchegoclient.PChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to add delegator %s", n.PAddress)
 	}
 
-	err = chainhelper.PChain().AwaitTransactionAcceptance(n.client, addDelegatorTxID, constants.TimeoutDuration)
+	err = chainhelper.PChain().AwaitTransactionAcceptance(client, addDelegatorTxID, constants.TimeoutDuration)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Failed to accept AddDelegator tx: %s", addDelegatorTxID))
-		return n
+		return stacktrace.Propagate(err, "Failed to accept AddDelegator tx: %s", addDelegatorTxID)
 	}
 
 	// Sleep until delegator starts validating
 	time.Sleep(time.Until(delegatorStartTime) + 3*time.Second)
 
 	expectedDelegatorBalance := seedAmount - delegatorAmount
-	err = chainhelper.PChain().CheckBalance(n.client, n.PAddress, expectedDelegatorBalance)
+	err = chainhelper.PChain().CheckBalance(client, n.PAddress, expectedDelegatorBalance)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Unexpected P Chain Balance after adding a new delegator to the network."))
-		return n
+		return stacktrace.Propagate(err, "Unexpected P Chain Balance after adding a new delegator to the network.")
 	}
 	logrus.Infof("Added delegator to subnet and verified the expected P Chain balance.")
 
-	return n
+	return nil
 }
 
+// GetIPAddress returns the IP address of the running node, "" if it isn't running
 func (n *Node) GetIPAddress() string {
-	return n.ipAddress
+	if n.lookupErr != nil {
+		return ""
+	}
+	ipAddress, err := n.network.GetIPAddress(n.id)
+	if err != nil {
+		return ""
+	}
+	return ipAddress
+}
+
+// Err returns the first error recorded while building the Topology this Node belongs to
+func (n *Node) Err() error {
+	return n.errs.Err()
 }
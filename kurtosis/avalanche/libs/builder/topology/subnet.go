@@ -0,0 +1,251 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package topology
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// subnetValidationDuration must fit in the primary network validation period of the nodes (see BecomeValidator)
+	subnetValidationDuration = 24 * time.Hour
+	subnetValidatorWeight    = 20
+)
+
+// Subnet is a subnet of the Topology, controlled by the P Chain address of a single Node
+// Nodes validating the Subnet must be primary network validators, restarted with the Subnet ID
+// whitelisted once it's created (see AvalancheNetwork.WhitelistSubnet)
+type Subnet struct {
+	id          string
+	SubnetID    ids.ID
+	controlNode *Node
+	validators  map[string]*Node
+	blockchains map[string]ids.ID
+	// lookupErr is set on the placeholder returned for a Subnet that wasn't added, see Topology.Subnet
+	lookupErr error
+	errs      *buildErrs
+}
+
+func newSubnet(id string, controlNode *Node, errs *buildErrs) *Subnet {
+	return &Subnet{
+		id:          id,
+		controlNode: controlNode,
+		validators:  map[string]*Node{},
+		blockchains: map[string]ids.ID{},
+		errs:        errs,
+	}
+}
+
+// create issues the CreateSubnet transaction with the control Node P Chain address as the only control key
+// the control Node pays the transaction fee from its P Chain balance
+func (s *Subnet) create() error {
+	client, err := s.controlNode.TryGetClient()
+	if err != nil {
+		return err
+	}
+
+	txID, err := client.PChainAPI().CreateSubnet(
+		s.controlNode.UserPass,
+		nil, // from addrs
+		"",  // change addr
+		[]string{s.controlNode.PAddress},
+		1,
+	)
+	if err != nil {
+		client.RecordTx(avalanchegoclient.PChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to create subnet %s", s.id)
+	}
+
+	err = chainhelper.PChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to accept CreateSubnet tx: %s", txID)
+	}
+
+	// the ID of the subnet is the ID of the tx that created it
+	s.SubnetID = txID
+	logrus.Infof("Created Subnet: %s SubnetID: %s", s.id, s.SubnetID)
+	return nil
+}
+
+// AddValidator adds [node] as a validator of the Subnet
+// Failures are recorded and can be retrieved with Err
+func (s *Subnet) AddValidator(node *Node) *Subnet {
+	if s.errs.Errored() {
+		return s
+	}
+	s.errs.Add(s.TryAddValidator(node))
+	return s
+}
+
+// TryAddValidator adds [node] as a validator of the Subnet
+// - issues an AddSubnetValidator tx signed by the control Node + waits for acceptance in the PChain
+// - waits until the validation period begins
+// - verifies the node is a current validator of the Subnet
+func (s *Subnet) TryAddValidator(node *Node) error {
+	if s.lookupErr != nil {
+		return s.lookupErr
+	}
+	if node.lookupErr != nil {
+		return node.lookupErr
+	}
+	client, err := s.controlNode.TryGetClient()
+	if err != nil {
+		return err
+	}
+	stakingStartTime := time.Now().Add(20 * time.Second)
+	startTime := uint64(stakingStartTime.Unix())
+	endTime := uint64(stakingStartTime.Add(subnetValidationDuration).Unix())
+	txID, err := client.PChainAPI().AddSubnetValidator(
+		s.controlNode.UserPass,
+		nil, // from addrs
+		"",  // change addr
+		s.SubnetID.String(),
+		node.NodeID,
+		subnetValidatorWeight,
+		startTime,
+		endTime,
+	)
+	if err != nil {
+		client.RecordTx(avalanchegoclient.PChain, "", avalanchegoclient.TxIssueFailed)
+		return stacktrace.Propagate(err, "Failed to add %s as a validator of subnet %s", node.NodeID, s.SubnetID)
+	}
+
+	err = chainhelper.PChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to accept AddSubnetValidator tx: %s", txID)
+	}
+
+	// waits until the validation period begins
+	time.Sleep(time.Until(stakingStartTime) + 3*time.Second)
+
+	currentValidators, err := client.PChainAPI().GetCurrentValidators(s.SubnetID)
+	if err != nil {
+		return stacktrace.Propagate(err, "Could not get current validators of subnet %s", s.SubnetID)
+	}
+
+	found := false
+	for _, validatorIntf := range currentValidators {
+		validator := validatorIntf.(map[string]interface{})
+		if validator["nodeID"] == node.NodeID {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return stacktrace.NewError("Node: %s not found in the subnet %s validators %v", node.NodeID, s.SubnetID, currentValidators)
+	}
+
+	s.validators[node.id] = node
+	logrus.Infof("Node: %s is validating Subnet: %s", node.NodeID, s.SubnetID)
+	return nil
+}
+
+// CreateBlockchain deploys a blockchain named [name] running [vmID] with [genesis] on the Subnet
+// Failures are recorded and can be retrieved with Err
+func (s *Subnet) CreateBlockchain(name string, vmID string, genesis []byte) *Subnet {
+	if s.errs.Errored() {
+		return s
+	}
+	_, err := s.TryCreateBlockchain(name, vmID, genesis)
+	s.errs.Add(err)
+	return s
+}
+
+// TryCreateBlockchain deploys a blockchain named [name] running [vmID] with [genesis] on the Subnet
+// and returns its ID
+func (s *Subnet) TryCreateBlockchain(name string, vmID string, genesis []byte) (ids.ID, error) {
+	if s.lookupErr != nil {
+		return ids.Empty, s.lookupErr
+	}
+	client, err := s.controlNode.TryGetClient()
+	if err != nil {
+		return ids.Empty, err
+	}
+	txID, err := client.PChainAPI().CreateBlockchain(
+		s.controlNode.UserPass,
+		nil, // from addrs
+		"",  // change addr
+		s.SubnetID,
+		vmID,
+		nil, // fx IDs
+		name,
+		genesis,
+	)
+	if err != nil {
+		client.RecordTx(avalanchegoclient.PChain, "", avalanchegoclient.TxIssueFailed)
+		return ids.Empty, stacktrace.Propagate(err, "Failed to create blockchain %s on subnet %s", name, s.SubnetID)
+	}
+
+	err = chainhelper.PChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration)
+	if err != nil {
+		return ids.Empty, stacktrace.Propagate(err, "Failed to accept CreateBlockchain tx: %s", txID)
+	}
+
+	// the ID of the blockchain is the ID of the tx that created it
+	s.blockchains[name] = txID
+	logrus.Infof("Created Blockchain: %s BlockchainID: %s on Subnet: %s", name, txID, s.SubnetID)
+	return txID, nil
+}
+
+// AwaitBlockchainBootstrapped waits for the blockchain [name] to be validated and bootstrapped
+// on every validator of the Subnet
+// Failures are recorded and can be retrieved with Err
+func (s *Subnet) AwaitBlockchainBootstrapped(name string, timeout time.Duration) *Subnet {
+	if s.errs.Errored() {
+		return s
+	}
+	s.errs.Add(s.TryAwaitBlockchainBootstrapped(name, timeout))
+	return s
+}
+
+// TryAwaitBlockchainBootstrapped is the error returning version of AwaitBlockchainBootstrapped
+func (s *Subnet) TryAwaitBlockchainBootstrapped(name string, timeout time.Duration) error {
+	if s.lookupErr != nil {
+		return s.lookupErr
+	}
+	blockchainID, ok := s.blockchains[name]
+	if !ok {
+		return stacktrace.NewError("Blockchain %s was not created on subnet %s", name, s.SubnetID)
+	}
+
+	for _, node := range s.validators {
+		client, err := node.TryGetClient()
+		if err != nil {
+			return err
+		}
+		err = chainhelper.PChain().AwaitBlockchainBootstrapped(client, blockchainID, timeout)
+		if err != nil {
+			return stacktrace.Propagate(err, "Blockchain %s not bootstrapped on node %s", name, node.NodeID)
+		}
+		logrus.Infof("Blockchain: %s is bootstrapped on Node: %s", blockchainID, node.NodeID)
+	}
+	return nil
+}
+
+// Blockchain returns the ID of the blockchain [name] created on the Subnet
+func (s *Subnet) Blockchain(name string) ids.ID {
+	return s.blockchains[name]
+}
+
+// GetValidators returns the Nodes validating the Subnet
+func (s *Subnet) GetValidators() []*Node {
+	var validators []*Node
+	for _, node := range s.validators {
+		validators = append(validators, node)
+	}
+	return validators
+}
+
+// Err returns the first error recorded while building the Topology this Subnet belongs to
+func (s *Subnet) Err() error {
+	return s.errs.Err()
+}
@@ -4,9 +4,15 @@
 package topology
 
 import (
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/wallet"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
 	"github.com/palantir/stacktrace"
 	"github.com/sirupsen/logrus"
@@ -15,70 +21,327 @@ import (
 // Topology defines how the nodes behave/capabilities in the network
 type Topology struct {
 	network *networksavalanche.AvalancheNetwork
-	genesis *Genesis
-	nodes   map[string]*Node
+	// genesisConfig holds the funded key and the chain IDs of the network
+	genesisConfig *constants.NetworkGenesisConfig
+	genesis       *Genesis
+	nodes         map[string]*Node
+	subnets       map[string]*Subnet
+	assets        map[string]*Asset
+	wallets       map[string]*wallet.Wallet
+	errs          *buildErrs
 }
 
 // New creates a new instance of the Topology
 func New(network networks.Network) *Topology {
 	avalancheNetwork := networksavalanche.Cast(network)
 	return &Topology{
-		network: avalancheNetwork,
-		nodes:   map[string]*Node{},
+		network:       avalancheNetwork,
+		genesisConfig: avalancheNetwork.GetGenesisConfig(),
+		nodes:         map[string]*Node{},
+		subnets:       map[string]*Subnet{},
+		assets:        map[string]*Asset{},
+		wallets:       map[string]*wallet.Wallet{},
+		errs:          &buildErrs{},
 	}
 }
 
 // AddNode adds a new now with both PChain and XChain address
+// Failures are recorded and can be retrieved with Err
 func (s *Topology) AddNode(id string, username string, password string) *Topology {
-	client, err := s.network.GetNodeClient(id)
-	if err != nil {
-		panic(stacktrace.Propagate(err, "Unable to fetch the Avalanche client"))
+	if s.errs.Errored() {
 		return s
 	}
+	_, err := s.TryAddNode(id, username, password)
+	s.errs.Add(err)
+	return s
+}
 
-	ipAddress, err := s.network.GetIPAddress(id)
+// TryAddNode adds a new node with both PChain and XChain address and returns it
+func (s *Topology) TryAddNode(id string, username string, password string) (*Node, error) {
+	newNode, err := newNode(id, username, password, s.network, s.genesisConfig.ChainIDs, s.errs)
 	if err != nil {
-		panic(stacktrace.Propagate(err, "Unable to fetch the Avalanche node IP address"))
-		return s
+		return nil, stacktrace.Propagate(err, "Unable to create node %s", id)
 	}
 
-	newNode := newNode(id, username, password, ipAddress, client).CreateAddress()
-	nodeID, err := client.InfoAPI().GetNodeID()
-	if err != nil {
-		panic(stacktrace.Propagate(err, "Unable to fetch the InfoAPI Node ID"))
-		return s
+	if err := newNode.createAddress(); err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to create the addresses of node %s", id)
 	}
 
-	logrus.Infof("New node in the Topology - Node: %s NodeID: %s", id, nodeID)
+	logrus.Infof("New node in the Topology - Node: %s NodeID: %s", id, newNode.NodeID)
 	s.nodes[id] = newNode
-	return s
+	return newNode, nil
 }
 
 // AddGenesis creates the Genesis property in the Topology
+// Failures are recorded and can be retrieved with Err
 func (s *Topology) AddGenesis(nodeID string, username string, password string) *Topology {
-	client, err := s.network.GetNodeClient(nodeID)
-	if err != nil {
-		panic(stacktrace.Propagate(err, "Unable to fetch the genesis Avalanche client"))
+	if s.errs.Errored() {
 		return s
 	}
+	_, err := s.TryAddGenesis(nodeID, username, password)
+	s.errs.Add(err)
+	return s
+}
 
-	s.genesis = newGenesis(nodeID, username, password, client)
-	err = s.genesis.ImportGenesisFunds()
-	if err != nil {
-		panic(stacktrace.Propagate(err, "Could not get delegator node ID."))
+// TryAddGenesis creates the Genesis property in the Topology and returns it
+func (s *Topology) TryAddGenesis(nodeID string, username string, password string) (*Genesis, error) {
+	if _, err := s.network.GetNodeClient(nodeID); err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to fetch the genesis Avalanche client")
 	}
 
-	return s
+	genesis := newGenesis(nodeID, username, password, s.network, s.genesisConfig, s.errs)
+	if err := genesis.ImportGenesisFunds(); err != nil {
+		return nil, stacktrace.Propagate(err, "Could not import the genesis funds.")
+	}
+
+	s.genesis = genesis
+	return genesis, nil
 }
 
 // Genesis returns the Topology Genesis
+// If no Genesis was added, an error is recorded and a placeholder is returned: its fluent calls are no-ops
+// and its Try methods fail with that error
 func (s *Topology) Genesis() *Genesis {
+	if s.genesis == nil {
+		err := stacktrace.NewError("No genesis was added to the topology")
+		s.errs.Add(err)
+		return &Genesis{lookupErr: err, errs: s.errs}
+	}
 	return s.genesis
 }
 
 // Node returns a Node given the [nodeID]
+// If no such Node was added, an error is recorded and a placeholder is returned: its fluent calls are no-ops
+// and its Try methods fail with that error
 func (s *Topology) Node(nodeID string) *Node {
-	return s.nodes[nodeID]
+	node, ok := s.nodes[nodeID]
+	if !ok {
+		err := stacktrace.NewError("Node %s was not added to the topology", nodeID)
+		s.errs.Add(err)
+		return &Node{id: nodeID, lookupErr: err, errs: s.errs}
+	}
+	return node
+}
+
+// AddSubnet creates a Subnet controlled by the Node [controlNodeID]
+// The control Node must have enough funds in the PChain to pay for the Subnet transactions
+// Its validators must then be restarted with its SubnetID whitelisted, see AvalancheNetwork.WhitelistSubnet
+// Failures are recorded and can be retrieved with Err
+func (s *Topology) AddSubnet(id string, controlNodeID string) *Topology {
+	if s.errs.Errored() {
+		return s
+	}
+	_, err := s.TryAddSubnet(id, controlNodeID)
+	s.errs.Add(err)
+	return s
+}
+
+// TryAddSubnet creates a Subnet controlled by the Node [controlNodeID] and returns it
+func (s *Topology) TryAddSubnet(id string, controlNodeID string) (*Subnet, error) {
+	if _, ok := s.subnets[id]; ok {
+		return nil, stacktrace.NewError("Subnet %s already exists in the topology", id)
+	}
+
+	controlNode, ok := s.nodes[controlNodeID]
+	if !ok {
+		return nil, stacktrace.NewError("Node %s was not added to the topology", controlNodeID)
+	}
+
+	subnet := newSubnet(id, controlNode, s.errs)
+	if err := subnet.create(); err != nil {
+		return nil, stacktrace.Propagate(err, "Could not create subnet %s", id)
+	}
+
+	s.subnets[id] = subnet
+	return subnet, nil
+}
+
+// Subnet returns a Subnet given the [id]
+// If no such Subnet was added, an error is recorded and a placeholder is returned: its fluent calls are no-ops
+// and its Try methods fail with that error
+func (s *Topology) Subnet(id string) *Subnet {
+	subnet, ok := s.subnets[id]
+	if !ok {
+		err := stacktrace.NewError("Subnet %s was not added to the topology", id)
+		s.errs.Add(err)
+		placeholder := newSubnet(id, nil, s.errs)
+		placeholder.lookupErr = err
+		return placeholder
+	}
+	return subnet
+}
+
+// AddFixedCapAsset creates the fixed cap asset [name] whose whole supply goes to [holders], keyed by X Chain address
+// The creator Node [creatorNodeID] pays the transaction fee from its X Chain AVAX balance
+// Failures are recorded and can be retrieved with Err
+func (s *Topology) AddFixedCapAsset(name string, symbol string, denomination byte, creatorNodeID string, holders map[string]uint64) *Topology {
+	if s.errs.Errored() {
+		return s
+	}
+	_, err := s.TryAddFixedCapAsset(name, symbol, denomination, creatorNodeID, holders)
+	s.errs.Add(err)
+	return s
+}
+
+// TryAddFixedCapAsset creates the fixed cap asset [name] and returns it
+func (s *Topology) TryAddFixedCapAsset(name string, symbol string, denomination byte, creatorNodeID string, holders map[string]uint64) (*Asset, error) {
+	asset, err := s.newAsset(name, creatorNodeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := asset.createFixedCap(symbol, denomination, holders); err != nil {
+		return nil, stacktrace.Propagate(err, "Could not create asset %s", name)
+	}
+
+	s.assets[name] = asset
+	return asset, nil
+}
+
+// AddVariableCapAsset creates the variable cap asset [name] that any of the [minterSets] can mint
+// The creator Node [creatorNodeID] pays the transaction fee from its X Chain AVAX balance
+// Failures are recorded and can be retrieved with Err
+func (s *Topology) AddVariableCapAsset(name string, symbol string, denomination byte, creatorNodeID string, minterSets ...MinterSet) *Topology {
+	if s.errs.Errored() {
+		return s
+	}
+	_, err := s.TryAddVariableCapAsset(name, symbol, denomination, creatorNodeID, minterSets...)
+	s.errs.Add(err)
+	return s
+}
+
+// TryAddVariableCapAsset creates the variable cap asset [name] and returns it
+func (s *Topology) TryAddVariableCapAsset(name string, symbol string, denomination byte, creatorNodeID string, minterSets ...MinterSet) (*Asset, error) {
+	asset, err := s.newAsset(name, creatorNodeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := asset.createVariableCap(symbol, denomination, minterSets); err != nil {
+		return nil, stacktrace.Propagate(err, "Could not create asset %s", name)
+	}
+
+	s.assets[name] = asset
+	return asset, nil
+}
+
+func (s *Topology) newAsset(name string, creatorNodeID string) (*Asset, error) {
+	if _, ok := s.assets[name]; ok {
+		return nil, stacktrace.NewError("Asset %s already exists in the topology", name)
+	}
+
+	creator, ok := s.nodes[creatorNodeID]
+	if !ok {
+		return nil, stacktrace.NewError("Node %s was not added to the topology", creatorNodeID)
+	}
+	return newAsset(name, creator, s.errs), nil
+}
+
+// Asset returns an Asset given its [name]
+// If no such Asset was added, an error is recorded and a placeholder is returned: its fluent calls are no-ops
+// and its Try methods fail with that error
+func (s *Topology) Asset(name string) *Asset {
+	asset, ok := s.assets[name]
+	if !ok {
+		err := stacktrace.NewError("Asset %s was not added to the topology", name)
+		s.errs.Add(err)
+		placeholder := newAsset(name, nil, s.errs)
+		placeholder.lookupErr = err
+		return placeholder
+	}
+	return asset
+}
+
+// AddWallet creates the Wallet [name] holding the [privateKeys] (PrivateKey-...), paying [txFee] per transaction
+// its funds can be spent through the client of any Node, e.g. the funded key of the genesis
+// Failures are recorded and can be retrieved with Err
+func (s *Topology) AddWallet(name string, txFee uint64, privateKeys ...string) *Topology {
+	if s.errs.Errored() {
+		return s
+	}
+	_, err := s.TryAddWallet(name, txFee, privateKeys...)
+	s.errs.Add(err)
+	return s
+}
+
+// TryAddWallet creates the Wallet [name] and returns it
+func (s *Topology) TryAddWallet(name string, txFee uint64, privateKeys ...string) (*wallet.Wallet, error) {
+	if _, ok := s.wallets[name]; ok {
+		return nil, stacktrace.NewError("Wallet %s already exists in the topology", name)
+	}
+
+	newWallet, err := wallet.NewFromFormattedKeys(s.genesisConfig.ChainIDs, txFee, privateKeys...)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Could not create wallet %s", name)
+	}
+
+	logrus.Infof("New wallet in the Topology - Wallet: %s XAddress: %s", name, newWallet.XAddress)
+	s.wallets[name] = newWallet
+	return newWallet, nil
+}
+
+// Wallet returns a Wallet given its [name]
+// If no such Wallet was added, an error is recorded and nil is returned: check Err before using the Wallet,
+// or use TryWallet
+func (s *Topology) Wallet(name string) *wallet.Wallet {
+	w, err := s.TryWallet(name)
+	s.errs.Add(err)
+	return w
+}
+
+// TryWallet returns a Wallet given its [name], or an error if no such Wallet was added
+func (s *Topology) TryWallet(name string) (*wallet.Wallet, error) {
+	w, ok := s.wallets[name]
+	if !ok {
+		return nil, stacktrace.NewError("Wallet %s was not added to the topology", name)
+	}
+	return w, nil
+}
+
+// AwaitConflictSetDecision waits for the conflicting X Chain [txIDs] to be decided the same way on the Genesis and
+// every Node of the Topology within [timeout]: exactly one of them accepted, the others rejected
+// Failures, with the decision table of the nodes, are recorded and can be retrieved with Err
+func (s *Topology) AwaitConflictSetDecision(txIDs []ids.ID, timeout time.Duration) *Topology {
+	if s.errs.Errored() {
+		return s
+	}
+	_, _, err := s.TryAwaitConflictSetDecision(txIDs, timeout)
+	s.errs.Add(err)
+	return s
+}
+
+// TryAwaitConflictSetDecision waits for the conflicting X Chain [txIDs] to be decided and returns the ID of the
+// accepted transaction, with the decisions of every node
+func (s *Topology) TryAwaitConflictSetDecision(txIDs []ids.ID, timeout time.Duration) (ids.ID, *chainhelper.ConflictSetDecisions, error) {
+	clients := s.GetClients()
+	if len(clients) == 0 {
+		return ids.Empty, nil, stacktrace.NewError("The topology has no node to await the conflict set on")
+	}
+
+	acceptedTxID, decisions, err := chainhelper.XChain().AwaitConflictSetDecision(clients, txIDs, timeout)
+	if err != nil {
+		return ids.Empty, decisions, stacktrace.Propagate(err, "The topology nodes didn't reach a single decision on the conflict set")
+	}
+	return acceptedTxID, decisions, nil
+}
+
+// GetChainIDs returns the network ID and the chain/asset IDs derived from the network genesis
+func (s *Topology) GetChainIDs() constants.ChainIDs {
+	return s.genesisConfig.ChainIDs
+}
+
+// GetClients returns the clients of the Genesis and of every Node of the Topology that are running, keyed by node ID
+func (s *Topology) GetClients() map[string]*avalanchegoclient.Client {
+	clients := map[string]*avalanchegoclient.Client{}
+	for id, node := range s.nodes {
+		if client, err := node.TryGetClient(); err == nil {
+			clients[id] = client
+		}
+	}
+	if s.genesis != nil {
+		if client, err := s.genesis.getClient(); err == nil {
+			clients[s.genesis.id] = client
+		}
+	}
+	return clients
 }
 
 func (s *Topology) GetAllNodes() []*Node {
@@ -100,3 +363,40 @@ func (s *Topology) LoadDefinedNetwork(definedNetwork *networkbuilder.Network) *T
 	}
 	return s
 }
+
+// Err returns the first error recorded by a fluent call on the Topology, its Genesis, its Nodes or its Subnets
+func (s *Topology) Err() error {
+	return s.errs.Err()
+}
+
+// Build returns the Topology, or the first error recorded while building it
+func (s *Topology) Build() (*Topology, error) {
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// buildErrs keeps the first error that happened while building a Topology
+// it's shared between the Topology, the Genesis and the Nodes so that once a
+// step fails, every following fluent call becomes a no-op
+type buildErrs struct {
+	err error
+}
+
+// Add records [err] if no error was recorded before
+func (b *buildErrs) Add(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Errored returns true if an error was recorded
+func (b *buildErrs) Errored() bool {
+	return b.err != nil
+}
+
+// Err returns the recorded error
+func (b *buildErrs) Err() error {
+	return b.err
+}
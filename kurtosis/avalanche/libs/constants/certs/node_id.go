@@ -0,0 +1,34 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package certs
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/palantir/stacktrace"
+)
+
+// NodeID returns the NodeID (e.g. NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg) avalanchego derives
+// from the staking cert [certPemBytes]
+func NodeID(certPemBytes []byte) (string, error) {
+	block, _ := pem.Decode(certPemBytes)
+	if block == nil {
+		return "", stacktrace.NewError("Staking cert is not PEM encoded")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Failed to parse the staking cert")
+	}
+
+	id, err := ids.ToShortID(hashing.PubkeyBytesToAddress(cert.Raw))
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Failed to derive the NodeID from the staking cert")
+	}
+	return id.PrefixedString(constants.NodeIDPrefix), nil
+}
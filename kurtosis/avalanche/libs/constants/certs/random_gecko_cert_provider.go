@@ -45,6 +45,9 @@ var rootCert = x509.Certificate{
 type RandomAvalancheCertProvider struct {
 	nextSerialNumber int64
 	varyCerts        bool
+	// the cert and key returned on every call when varyCerts is false
+	cert bytes.Buffer
+	key  bytes.Buffer
 }
 
 // NewRandomAvalancheCertProvider creates a new cert provider that can optionally return either the same cert every time, or different ones
@@ -64,6 +67,11 @@ func NewRandomAvalancheCertProvider(varyCerts bool) *RandomAvalancheCertProvider
 // 	certPemBytes: The bytes of the generated cert
 // 	keyPemBytes: The bytes of the private key that was generated alongside the cert
 func (r *RandomAvalancheCertProvider) GetCertAndKey() (certPemBytes bytes.Buffer, keyPemBytes bytes.Buffer, err error) {
+	// the same key must be reused too, otherwise the cert (and the NodeID derived from it) changes
+	if !r.varyCerts && r.cert.Len() > 0 {
+		return r.cert, r.key, nil
+	}
+
 	serialNum := r.nextSerialNumber
 	if r.varyCerts {
 		r.nextSerialNumber = mathrand.Int63() // nolint:gosec
@@ -93,6 +101,10 @@ func (r *RandomAvalancheCertProvider) GetCertAndKey() (certPemBytes bytes.Buffer
 	}); err != nil {
 		return bytes.Buffer{}, bytes.Buffer{}, err
 	}
+
+	if !r.varyCerts {
+		r.cert, r.key = *certPEM, *certPrivKeyPEM
+	}
 	return *certPEM, *certPrivKeyPEM, nil
 }
 
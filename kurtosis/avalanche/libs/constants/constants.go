@@ -17,6 +17,10 @@ const (
 	VERBOSE AvalancheLogLevel = "verbo"
 	DEBUG   AvalancheLogLevel = "debug"
 	INFO    AvalancheLogLevel = "info"
+	WARN    AvalancheLogLevel = "warn"
+	ERROR   AvalancheLogLevel = "error"
+	FATAL   AvalancheLogLevel = "fatal"
+	OFF     AvalancheLogLevel = "off"
 
 	NetworkID            uint32 = 12345
 	StakingTLSCertFileID        = "staking-tls-cert"
@@ -27,6 +31,8 @@ const (
 	DefaultPassword = "This1sSuper!S4f3!..."
 )
 
+// the IDs of the local network, see DefaultLocalNetGenesisConfig.ChainIDs for networks
+// that may use a custom genesis
 var (
 	// XChainID ...
 	XChainID ids.ID
@@ -43,4 +49,22 @@ func init() {
 	PlatformChainID = ids.Empty
 	CChainID, _ = ids.FromString("WKNkfmNxgqpKPe9Q12UCoTuGYXX5JbQn2tf2WTpNTJeQrezqa")
 	AvaxAssetID, _ = ids.FromString("2fombhL7aGPwj3KH4bfrmJwW6PVnMobf9Y2fn9GwxiAAJyFDbe")
+
+	DefaultLocalNetGenesisConfig.ChainIDs = ChainIDs{
+		NetworkID:       NetworkID,
+		XChainID:        XChainID,
+		PlatformChainID: PlatformChainID,
+		CChainID:        CChainID,
+		AvaxAssetID:     AvaxAssetID,
+	}
+}
+
+// IsValidLogLevel returns true if [level] is a log level avalanchego accepts
+func IsValidLogLevel(level AvalancheLogLevel) bool {
+	switch level {
+	case VERBOSE, DEBUG, INFO, WARN, ERROR, FATAL, OFF:
+		return true
+	default:
+		return false
+	}
 }
@@ -3,11 +3,23 @@
 
 package constants
 
+import "github.com/ava-labs/avalanchego/ids"
+
 // NetworkGenesisConfig encapsulates genesis information describing
 // a network
 type NetworkGenesisConfig struct {
 	Stakers         []StakerIdentity
 	FundedAddresses FundedAddress
+	ChainIDs        ChainIDs
+}
+
+// ChainIDs contains the network ID and the chain/asset IDs derived from the genesis of a network
+type ChainIDs struct {
+	NetworkID       uint32
+	XChainID        ids.ID
+	PlatformChainID ids.ID
+	CChainID        ids.ID
+	AvaxAssetID     ids.ID
 }
 
 // FundedAddress encapsulates a pre-funded address
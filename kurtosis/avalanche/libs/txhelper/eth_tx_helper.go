@@ -0,0 +1,59 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txhelper
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EthTransferGas is the gas used by a plain EVM transfer
+const EthTransferGas = 21000
+
+// EthCall is an EVM transaction: a transfer of [Value] wei if [Data] is empty, a contract call otherwise
+// or a contract creation from the bytecode in [Data] if [To] is nil
+type EthCall struct {
+	To    *common.Address
+	Value *big.Int
+	Data  []byte
+	Gas   uint64
+}
+
+// CreateEthTx returns [call] sent with [nonce] and [gasPrice], signed by [privateKey] with the EIP-155 signer of [chainID]
+func CreateEthTx(chainID *big.Int, nonce uint64, call EthCall, gasPrice *big.Int, privateKey *crypto.PrivateKeySECP256K1R) (*types.Transaction, error) {
+	value := call.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	var tx *types.Transaction
+	if call.To == nil {
+		tx = types.NewContractCreation(nonce, value, call.Gas, gasPrice, call.Data)
+	} else {
+		tx = types.NewTransaction(nonce, *call.To, value, call.Gas, gasPrice, call.Data)
+	}
+
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey.ToECDSA())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the EVM transaction: %w", err)
+	}
+	return signedTx, nil
+}
+
+// CreateConsecutiveEthTxs returns [numTxs] copies of [call] with consecutive nonces, starting at [nonce]
+func CreateConsecutiveEthTxs(chainID *big.Int, nonce uint64, numTxs int, call EthCall, gasPrice *big.Int, privateKey *crypto.PrivateKeySECP256K1R) ([]*types.Transaction, error) {
+	txs := make([]*types.Transaction, 0, numTxs)
+	for i := 0; i < numTxs; i++ {
+		tx, err := CreateEthTx(chainID, nonce+uint64(i), call, gasPrice, privateKey)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
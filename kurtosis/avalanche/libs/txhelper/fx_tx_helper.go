@@ -0,0 +1,292 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txhelper
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/nftfx"
+	"github.com/ava-labs/avalanchego/vms/propertyfx"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+const (
+	// codecVersion is the X Chain codec version transactions are serialized with
+	codecVersion = 0
+
+	// indices of the feature extensions in the X Chain genesis
+	secp256k1FxIndex = 0
+	nftFxIndex       = 1
+	propertyFxIndex  = 2
+)
+
+// FeePayment is the AVAX [UTXO], holding [Amount] and owned by [PrivateKey], spent to pay the [TxFee] of a
+// transaction, the change goes back to the key
+type FeePayment struct {
+	UTXO       *avax.UTXO
+	Amount     uint64
+	TxFee      uint64
+	PrivateKey *crypto.PrivateKeySECP256K1R
+}
+
+// signer holds the keys signing an input or an operation, in the order of its signature indices
+// and the feature extension that verifies the signatures
+type signer struct {
+	fxIndex int
+	keys    []*crypto.PrivateKeySECP256K1R
+}
+
+// CreateNFTAssetTx returns a transaction creating an NFT asset on the X Chain of the network [chainIDs]
+// Each of [minters] is given a mint output of its own group, the group ID is its index in [minters]
+func CreateNFTAssetTx(chainIDs constants.ChainIDs, fee FeePayment, name, symbol string, minters []*secp256k1fx.OutputOwners, codec codec.Manager) (*avm.Tx, error) {
+	outs := make([]verify.State, 0, len(minters))
+	for groupID, minter := range minters {
+		outs = append(outs, &nftfx.MintOutput{
+			GroupID:      uint32(groupID),
+			OutputOwners: *minter,
+		})
+	}
+	return createAssetTx(chainIDs, fee, name, symbol, &avm.InitialState{FxID: nftFxIndex, Outs: outs}, codec)
+}
+
+// CreatePropertyAssetTx returns a transaction creating a property asset on the X Chain of the network [chainIDs]
+// Each of [minters] is given a mint output
+func CreatePropertyAssetTx(chainIDs constants.ChainIDs, fee FeePayment, name, symbol string, minters []*secp256k1fx.OutputOwners, codec codec.Manager) (*avm.Tx, error) {
+	outs := make([]verify.State, 0, len(minters))
+	for _, minter := range minters {
+		outs = append(outs, &propertyfx.MintOutput{OutputOwners: *minter})
+	}
+	return createAssetTx(chainIDs, fee, name, symbol, &avm.InitialState{FxID: propertyFxIndex, Outs: outs}, codec)
+}
+
+// CreateMintNFTTx returns a transaction spending the nftfx mint [mintUTXO] of [assetID] to mint an NFT carrying
+// [payload] for each of [to], the mint output is spent by [keys] and not given back
+func CreateMintNFTTx(chainIDs constants.ChainIDs, fee FeePayment, assetID ids.ID, mintUTXO *avax.UTXO, payload []byte, to []*secp256k1fx.OutputOwners, keys []*crypto.PrivateKeySECP256K1R, codec codec.Manager) (*avm.Tx, error) {
+	mintOutput, ok := mintUTXO.Out.(*nftfx.MintOutput)
+	if !ok {
+		return nil, fmt.Errorf("UTXO %s is not an NFT mint output", mintUTXO.InputID())
+	}
+	input, signingKeys, err := spend(&mintOutput.OutputOwners, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &nftfx.MintOperation{
+		MintInput: input,
+		GroupID:   mintOutput.GroupID,
+		Payload:   payload,
+		Outputs:   to,
+	}
+	return createOperationTx(chainIDs, fee, assetID, mintUTXO, op, signer{fxIndex: nftFxIndex, keys: signingKeys}, codec)
+}
+
+// CreateTransferNFTTx returns a transaction sending the NFT [nftUTXO] of [assetID], owned by [keys], to [to]
+func CreateTransferNFTTx(chainIDs constants.ChainIDs, fee FeePayment, assetID ids.ID, nftUTXO *avax.UTXO, to *secp256k1fx.OutputOwners, keys []*crypto.PrivateKeySECP256K1R, codec codec.Manager) (*avm.Tx, error) {
+	nftOutput, ok := nftUTXO.Out.(*nftfx.TransferOutput)
+	if !ok {
+		return nil, fmt.Errorf("UTXO %s is not an NFT", nftUTXO.InputID())
+	}
+	input, signingKeys, err := spend(&nftOutput.OutputOwners, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &nftfx.TransferOperation{
+		Input: input,
+		Output: nftfx.TransferOutput{
+			GroupID:      nftOutput.GroupID,
+			Payload:      nftOutput.Payload,
+			OutputOwners: *to,
+		},
+	}
+	return createOperationTx(chainIDs, fee, assetID, nftUTXO, op, signer{fxIndex: nftFxIndex, keys: signingKeys}, codec)
+}
+
+// CreateMintPropertyTx returns a transaction spending the propertyfx mint [mintUTXO] of [assetID] to give a property
+// to [to], the mint output is spent by [keys] and given back to its owners so they can mint again
+func CreateMintPropertyTx(chainIDs constants.ChainIDs, fee FeePayment, assetID ids.ID, mintUTXO *avax.UTXO, to *secp256k1fx.OutputOwners, keys []*crypto.PrivateKeySECP256K1R, codec codec.Manager) (*avm.Tx, error) {
+	mintOutput, ok := mintUTXO.Out.(*propertyfx.MintOutput)
+	if !ok {
+		return nil, fmt.Errorf("UTXO %s is not a property mint output", mintUTXO.InputID())
+	}
+	input, signingKeys, err := spend(&mintOutput.OutputOwners, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &propertyfx.MintOperation{
+		MintInput:   input,
+		MintOutput:  *mintOutput,
+		OwnedOutput: propertyfx.OwnedOutput{OutputOwners: *to},
+	}
+	return createOperationTx(chainIDs, fee, assetID, mintUTXO, op, signer{fxIndex: propertyFxIndex, keys: signingKeys}, codec)
+}
+
+// CreateBurnPropertyTx returns a transaction burning the property [ownedUTXO] of [assetID], owned by [keys]
+func CreateBurnPropertyTx(chainIDs constants.ChainIDs, fee FeePayment, assetID ids.ID, ownedUTXO *avax.UTXO, keys []*crypto.PrivateKeySECP256K1R, codec codec.Manager) (*avm.Tx, error) {
+	ownedOutput, ok := ownedUTXO.Out.(*propertyfx.OwnedOutput)
+	if !ok {
+		return nil, fmt.Errorf("UTXO %s is not a property", ownedUTXO.InputID())
+	}
+	input, signingKeys, err := spend(&ownedOutput.OutputOwners, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &propertyfx.BurnOperation{Input: input}
+	return createOperationTx(chainIDs, fee, assetID, ownedUTXO, op, signer{fxIndex: propertyFxIndex, keys: signingKeys}, codec)
+}
+
+func createAssetTx(chainIDs constants.ChainIDs, fee FeePayment, name, symbol string, state *avm.InitialState, codec codec.Manager) (*avm.Tx, error) {
+	baseTx, feeSigner, err := createFeeBaseTx(chainIDs, fee)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Sort(codec)
+	tx := &avm.Tx{UnsignedTx: &avm.CreateAssetTx{
+		BaseTx: baseTx,
+		Name:   name,
+		Symbol: symbol,
+		States: []*avm.InitialState{state},
+	}}
+	if err := sign(tx, codec, []signer{feeSigner}); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func createOperationTx(chainIDs constants.ChainIDs, fee FeePayment, assetID ids.ID, utxo *avax.UTXO, op avm.FxOperation, opSigner signer, codec codec.Manager) (*avm.Tx, error) {
+	baseTx, feeSigner, err := createFeeBaseTx(chainIDs, fee)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &avm.Tx{UnsignedTx: &avm.OperationTx{
+		BaseTx: baseTx,
+		Ops: []*avm.Operation{{
+			Asset:   avax.Asset{ID: assetID},
+			UTXOIDs: []*avax.UTXOID{&utxo.UTXOID},
+			Op:      op,
+		}},
+	}}
+	// the credentials of the inputs come first, then the ones of the operations
+	if err := sign(tx, codec, []signer{feeSigner, opSigner}); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// createFeeBaseTx returns a BaseTx spending the [fee] UTXO to pay the transaction fee
+func createFeeBaseTx(chainIDs constants.ChainIDs, fee FeePayment) (avm.BaseTx, signer, error) {
+	if fee.Amount < fee.TxFee {
+		return avm.BaseTx{}, signer{}, fmt.Errorf("fee UTXO amount %d can't pay the tx fee %d", fee.Amount, fee.TxFee)
+	}
+
+	var outs []*avax.TransferableOutput
+	if change := fee.Amount - fee.TxFee; change > 0 {
+		outs = append(outs, &avax.TransferableOutput{
+			Asset: avax.Asset{ID: chainIDs.AvaxAssetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: change,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{fee.PrivateKey.PublicKey().Address()},
+				},
+			},
+		})
+	}
+
+	ins := []*avax.TransferableInput{
+		{
+			UTXOID: fee.UTXO.UTXOID,
+			Asset:  avax.Asset{ID: chainIDs.AvaxAssetID},
+			In: &secp256k1fx.TransferInput{
+				Amt: fee.Amount,
+				Input: secp256k1fx.Input{
+					SigIndices: []uint32{0},
+				},
+			},
+		},
+	}
+
+	baseTx := avm.BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    chainIDs.NetworkID,
+		BlockchainID: chainIDs.XChainID,
+		Outs:         outs,
+		Ins:          ins,
+	}}
+	return baseTx, signer{fxIndex: secp256k1FxIndex, keys: []*crypto.PrivateKeySECP256K1R{fee.PrivateKey}}, nil
+}
+
+// spend returns the input spending an output owned by [owners], and the [keys] signing it in the order of its
+// signature indices, only as many keys as the [owners] threshold are used
+func spend(owners *secp256k1fx.OutputOwners, keys []*crypto.PrivateKeySECP256K1R) (secp256k1fx.Input, []*crypto.PrivateKeySECP256K1R, error) {
+	keysByAddress := map[ids.ShortID]*crypto.PrivateKeySECP256K1R{}
+	for _, key := range keys {
+		keysByAddress[key.PublicKey().Address()] = key
+	}
+
+	input := secp256k1fx.Input{}
+	var signingKeys []*crypto.PrivateKeySECP256K1R
+	for i, address := range owners.Addrs {
+		if uint32(len(signingKeys)) == owners.Threshold {
+			break
+		}
+		if key, ok := keysByAddress[address]; ok {
+			input.SigIndices = append(input.SigIndices, uint32(i))
+			signingKeys = append(signingKeys, key)
+		}
+	}
+	if uint32(len(signingKeys)) != owners.Threshold {
+		return secp256k1fx.Input{}, nil, fmt.Errorf("%d of the keys own the output, its threshold is %d", len(signingKeys), owners.Threshold)
+	}
+	return input, signingKeys, nil
+}
+
+// sign adds a credential per [signers] to [tx], of the type expected by the signer feature extension
+func sign(tx *avm.Tx, c codec.Manager, signers []signer) error {
+	unsignedBytes, err := c.Marshal(codecVersion, &tx.UnsignedTx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+
+	hash := hashing.ComputeHash256(unsignedBytes)
+	for _, s := range signers {
+		cred := secp256k1fx.Credential{
+			Sigs: make([][crypto.SECP256K1RSigLen]byte, len(s.keys)),
+		}
+		for i, key := range s.keys {
+			sig, err := key.SignHash(hash)
+			if err != nil {
+				return fmt.Errorf("problem creating transaction: %w", err)
+			}
+			copy(cred.Sigs[i][:], sig)
+		}
+
+		switch s.fxIndex {
+		case nftFxIndex:
+			tx.Creds = append(tx.Creds, &nftfx.Credential{Credential: cred})
+		case propertyFxIndex:
+			tx.Creds = append(tx.Creds, &propertyfx.Credential{Credential: cred})
+		default:
+			tx.Creds = append(tx.Creds, &cred)
+		}
+	}
+
+	signedBytes, err := c.Marshal(codecVersion, tx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+	tx.Initialize(unsignedBytes, signedBytes)
+	return nil
+}
@@ -0,0 +1,377 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txhelper
+
+import (
+	"bytes"
+	"testing"
+
+	helpers "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/codecs"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/nftfx"
+	"github.com/ava-labs/avalanchego/vms/propertyfx"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+const (
+	testTxFee     = 1000
+	testFeeAmount = 5000
+	numFxs        = 3
+)
+
+var testChainIDs = constants.ChainIDs{
+	NetworkID:   12345,
+	XChainID:    ids.GenerateTestID(),
+	AvaxAssetID: ids.GenerateTestID(),
+}
+
+func newTestKey(t *testing.T) *crypto.PrivateKeySECP256K1R {
+	t.Helper()
+
+	factory := crypto.FactorySECP256K1R{}
+	key, err := factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key.(*crypto.PrivateKeySECP256K1R)
+}
+
+func newTestUTXO(assetID ids.ID, out verify.State) *avax.UTXO {
+	return &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: assetID},
+		Out:    out,
+	}
+}
+
+func ownedBy(threshold uint32, keys ...*crypto.PrivateKeySECP256K1R) *secp256k1fx.OutputOwners {
+	owners := &secp256k1fx.OutputOwners{Threshold: threshold}
+	for _, key := range keys {
+		owners.Addrs = append(owners.Addrs, key.PublicKey().Address())
+	}
+	ids.SortShortIDs(owners.Addrs)
+	return owners
+}
+
+func newFeePayment(key *crypto.PrivateKeySECP256K1R, amount uint64) FeePayment {
+	return FeePayment{
+		UTXO: newTestUTXO(testChainIDs.AvaxAssetID, &secp256k1fx.TransferOutput{
+			Amt:          amount,
+			OutputOwners: *ownedBy(1, key),
+		}),
+		Amount:     amount,
+		TxFee:      testTxFee,
+		PrivateKey: key,
+	}
+}
+
+func TestFxTxs(t *testing.T) {
+	c, err := helpers.CreateXChainCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feeKey := newTestKey(t)
+	minter1, minter2, minter3 := newTestKey(t), newTestKey(t), newTestKey(t)
+	receiver := newTestKey(t)
+	assetID := ids.GenerateTestID()
+	fee := newFeePayment(feeKey, testFeeAmount)
+	exactFee := newFeePayment(feeKey, testTxFee)
+
+	tests := []struct {
+		name string
+		fee  FeePayment
+		// create builds the transaction
+		create func() (*avm.Tx, error)
+		// signers are the keys expected to sign each credential, the fee credential first
+		signers [][]*crypto.PrivateKeySECP256K1R
+	}{
+		{
+			name: "nft asset",
+			fee:  fee,
+			create: func() (*avm.Tx, error) {
+				return CreateNFTAssetTx(testChainIDs, fee, "Test NFT", "TNFT", []*secp256k1fx.OutputOwners{ownedBy(1, minter1), ownedBy(1, minter2)}, c)
+			},
+			signers: [][]*crypto.PrivateKeySECP256K1R{{feeKey}},
+		},
+		{
+			name: "property asset without change",
+			fee:  exactFee,
+			create: func() (*avm.Tx, error) {
+				return CreatePropertyAssetTx(testChainIDs, exactFee, "Test Property", "TPRP", []*secp256k1fx.OutputOwners{ownedBy(1, minter1)}, c)
+			},
+			signers: [][]*crypto.PrivateKeySECP256K1R{{feeKey}},
+		},
+		{
+			name: "mint nft",
+			fee:  fee,
+			create: func() (*avm.Tx, error) {
+				mintUTXO := newTestUTXO(assetID, &nftfx.MintOutput{GroupID: 1, OutputOwners: *ownedBy(2, minter1, minter2, minter3)})
+				return CreateMintNFTTx(testChainIDs, fee, assetID, mintUTXO, []byte("payload"), []*secp256k1fx.OutputOwners{ownedBy(1, receiver)}, []*crypto.PrivateKeySECP256K1R{minter1, minter2, minter3}, c)
+			},
+			signers: [][]*crypto.PrivateKeySECP256K1R{{feeKey}, signingOrder(2, minter1, minter2, minter3)},
+		},
+		{
+			name: "transfer nft",
+			fee:  fee,
+			create: func() (*avm.Tx, error) {
+				nftUTXO := newTestUTXO(assetID, &nftfx.TransferOutput{GroupID: 1, Payload: []byte("payload"), OutputOwners: *ownedBy(1, minter1)})
+				return CreateTransferNFTTx(testChainIDs, fee, assetID, nftUTXO, ownedBy(1, receiver), []*crypto.PrivateKeySECP256K1R{minter1}, c)
+			},
+			signers: [][]*crypto.PrivateKeySECP256K1R{{feeKey}, {minter1}},
+		},
+		{
+			name: "mint property",
+			fee:  fee,
+			create: func() (*avm.Tx, error) {
+				mintUTXO := newTestUTXO(assetID, &propertyfx.MintOutput{OutputOwners: *ownedBy(1, minter1)})
+				return CreateMintPropertyTx(testChainIDs, fee, assetID, mintUTXO, ownedBy(1, receiver), []*crypto.PrivateKeySECP256K1R{minter1}, c)
+			},
+			signers: [][]*crypto.PrivateKeySECP256K1R{{feeKey}, {minter1}},
+		},
+		{
+			name: "burn property",
+			fee:  fee,
+			create: func() (*avm.Tx, error) {
+				ownedUTXO := newTestUTXO(assetID, &propertyfx.OwnedOutput{OutputOwners: *ownedBy(1, receiver)})
+				return CreateBurnPropertyTx(testChainIDs, fee, assetID, ownedUTXO, []*crypto.PrivateKeySECP256K1R{receiver}, c)
+			},
+			signers: [][]*crypto.PrivateKeySECP256K1R{{feeKey}, {receiver}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx, err := test.create()
+			if err != nil {
+				t.Fatalf("failed to create the tx: %v", err)
+			}
+
+			checkTxBytes(t, c, tx)
+			checkCredentials(t, c, tx, test.signers)
+			checkFeeChange(t, tx, test.fee)
+
+			ctx := snow.DefaultContextTest()
+			ctx.NetworkID = testChainIDs.NetworkID
+			ctx.ChainID = testChainIDs.XChainID
+			if err := tx.SyntacticVerify(ctx, c, testChainIDs.AvaxAssetID, testTxFee, testTxFee, numFxs); err != nil {
+				t.Fatalf("tx isn't valid: %v", err)
+			}
+		})
+	}
+}
+
+func TestFxTxsErrors(t *testing.T) {
+	c, err := helpers.CreateXChainCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feeKey, owner, other := newTestKey(t), newTestKey(t), newTestKey(t)
+	assetID := ids.GenerateTestID()
+	fee := newFeePayment(feeKey, testFeeAmount)
+
+	tests := []struct {
+		name   string
+		create func() (*avm.Tx, error)
+	}{
+		{
+			name: "fee too small",
+			create: func() (*avm.Tx, error) {
+				return CreateNFTAssetTx(testChainIDs, newFeePayment(feeKey, testTxFee-1), "Test NFT", "TNFT", []*secp256k1fx.OutputOwners{ownedBy(1, owner)}, c)
+			},
+		},
+		{
+			name: "mint nft from a property mint output",
+			create: func() (*avm.Tx, error) {
+				mintUTXO := newTestUTXO(assetID, &propertyfx.MintOutput{OutputOwners: *ownedBy(1, owner)})
+				return CreateMintNFTTx(testChainIDs, fee, assetID, mintUTXO, nil, []*secp256k1fx.OutputOwners{ownedBy(1, other)}, []*crypto.PrivateKeySECP256K1R{owner}, c)
+			},
+		},
+		{
+			name: "transfer an nft mint output",
+			create: func() (*avm.Tx, error) {
+				mintUTXO := newTestUTXO(assetID, &nftfx.MintOutput{OutputOwners: *ownedBy(1, owner)})
+				return CreateTransferNFTTx(testChainIDs, fee, assetID, mintUTXO, ownedBy(1, other), []*crypto.PrivateKeySECP256K1R{owner}, c)
+			},
+		},
+		{
+			name: "mint property from an owned output",
+			create: func() (*avm.Tx, error) {
+				ownedUTXO := newTestUTXO(assetID, &propertyfx.OwnedOutput{OutputOwners: *ownedBy(1, owner)})
+				return CreateMintPropertyTx(testChainIDs, fee, assetID, ownedUTXO, ownedBy(1, other), []*crypto.PrivateKeySECP256K1R{owner}, c)
+			},
+		},
+		{
+			name: "burn a property mint output",
+			create: func() (*avm.Tx, error) {
+				mintUTXO := newTestUTXO(assetID, &propertyfx.MintOutput{OutputOwners: *ownedBy(1, owner)})
+				return CreateBurnPropertyTx(testChainIDs, fee, assetID, mintUTXO, []*crypto.PrivateKeySECP256K1R{owner}, c)
+			},
+		},
+		{
+			name: "keys below the threshold",
+			create: func() (*avm.Tx, error) {
+				mintUTXO := newTestUTXO(assetID, &nftfx.MintOutput{OutputOwners: *ownedBy(2, owner, other)})
+				return CreateMintNFTTx(testChainIDs, fee, assetID, mintUTXO, nil, []*secp256k1fx.OutputOwners{ownedBy(1, other)}, []*crypto.PrivateKeySECP256K1R{owner}, c)
+			},
+		},
+		{
+			name: "keys not owning the output",
+			create: func() (*avm.Tx, error) {
+				ownedUTXO := newTestUTXO(assetID, &propertyfx.OwnedOutput{OutputOwners: *ownedBy(1, owner)})
+				return CreateBurnPropertyTx(testChainIDs, fee, assetID, ownedUTXO, []*crypto.PrivateKeySECP256K1R{other}, c)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.create(); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+// signingOrder returns the first [threshold] of [keys] in the order of the output addresses
+func signingOrder(threshold int, keys ...*crypto.PrivateKeySECP256K1R) []*crypto.PrivateKeySECP256K1R {
+	keysByAddress := map[ids.ShortID]*crypto.PrivateKeySECP256K1R{}
+	for _, key := range keys {
+		keysByAddress[key.PublicKey().Address()] = key
+	}
+
+	ordered := make([]*crypto.PrivateKeySECP256K1R, 0, threshold)
+	for _, address := range ownedBy(uint32(threshold), keys...).Addrs[:threshold] {
+		ordered = append(ordered, keysByAddress[address])
+	}
+	return ordered
+}
+
+// checkTxBytes checks the [tx] ID is the hash of its bytes, and the bytes parse back to the same tx
+func checkTxBytes(t *testing.T, c codec.Manager, tx *avm.Tx) {
+	t.Helper()
+
+	if expected := ids.ID(hashing.ComputeHash256Array(tx.Bytes())); tx.ID() != expected {
+		t.Fatalf("tx ID is %s, expected the hash of its bytes %s", tx.ID(), expected)
+	}
+
+	parsed := avm.Tx{}
+	if _, err := c.Unmarshal(tx.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse the tx bytes: %v", err)
+	}
+	reparsedBytes, err := c.Marshal(codecVersion, &parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reparsedBytes, tx.Bytes()) {
+		t.Fatal("parsed tx bytes differ from the tx bytes")
+	}
+
+	unsignedBytes, err := c.Marshal(codecVersion, &tx.UnsignedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unsignedBytes, tx.UnsignedBytes()) {
+		t.Fatal("tx unsigned bytes aren't the serialized unsigned tx")
+	}
+}
+
+// checkCredentials checks [tx] has a credential per [signers], of the type of the signed fx
+// and holding the signatures of the unsigned tx by the signer keys
+func checkCredentials(t *testing.T, c codec.Manager, tx *avm.Tx, signers [][]*crypto.PrivateKeySECP256K1R) {
+	t.Helper()
+
+	if len(tx.Creds) != len(signers) {
+		t.Fatalf("tx has %d credentials, expected %d", len(tx.Creds), len(signers))
+	}
+
+	hash := hashing.ComputeHash256(tx.UnsignedBytes())
+	factory := crypto.FactorySECP256K1R{}
+	for i, cred := range tx.Creds {
+		var sigs [][crypto.SECP256K1RSigLen]byte
+		switch cred := cred.(type) {
+		case *secp256k1fx.Credential:
+			if i != 0 {
+				t.Fatalf("credential %d is a secp256k1fx credential, expected an operation credential", i)
+			}
+			sigs = cred.Sigs
+		case *nftfx.Credential:
+			switch tx.UnsignedTx.(*avm.OperationTx).Ops[i-1].Op.(type) {
+			case *nftfx.MintOperation, *nftfx.TransferOperation:
+			default:
+				t.Fatalf("credential %d is an nftfx credential for a non nftfx operation", i)
+			}
+			sigs = cred.Sigs
+		case *propertyfx.Credential:
+			switch tx.UnsignedTx.(*avm.OperationTx).Ops[i-1].Op.(type) {
+			case *propertyfx.MintOperation, *propertyfx.BurnOperation:
+			default:
+				t.Fatalf("credential %d is a propertyfx credential for a non propertyfx operation", i)
+			}
+			sigs = cred.Sigs
+		default:
+			t.Fatalf("credential %d has unexpected type %T", i, cred)
+		}
+
+		if len(sigs) != len(signers[i]) {
+			t.Fatalf("credential %d has %d signatures, expected %d", i, len(sigs), len(signers[i]))
+		}
+		for j, sig := range sigs {
+			publicKey, err := factory.RecoverHashPublicKey(hash, sig[:])
+			if err != nil {
+				t.Fatalf("failed to recover the signer of credential %d signature %d: %v", i, j, err)
+			}
+			if publicKey.Address() != signers[i][j].PublicKey().Address() {
+				t.Fatalf("credential %d signature %d is by %s, expected %s", i, j, publicKey.Address(), signers[i][j].PublicKey().Address())
+			}
+		}
+	}
+}
+
+// checkFeeChange checks [tx] spends the [fee] UTXO and gives the change, if any, back to the fee key
+func checkFeeChange(t *testing.T, tx *avm.Tx, fee FeePayment) {
+	t.Helper()
+
+	var baseTx avm.BaseTx
+	switch unsignedTx := tx.UnsignedTx.(type) {
+	case *avm.CreateAssetTx:
+		baseTx = unsignedTx.BaseTx
+	case *avm.OperationTx:
+		baseTx = unsignedTx.BaseTx
+	default:
+		t.Fatalf("unexpected tx type %T", unsignedTx)
+	}
+
+	if len(baseTx.Ins) != 1 || baseTx.Ins[0].InputID() != fee.UTXO.InputID() || baseTx.Ins[0].In.Amount() != fee.Amount {
+		t.Fatalf("tx doesn't spend exactly the fee UTXO %s", fee.UTXO.InputID())
+	}
+
+	change := fee.Amount - fee.TxFee
+	if change == 0 {
+		if len(baseTx.Outs) != 0 {
+			t.Fatalf("tx has %d outputs, expected no change", len(baseTx.Outs))
+		}
+		return
+	}
+	if len(baseTx.Outs) != 1 {
+		t.Fatalf("tx has %d outputs, expected the change output", len(baseTx.Outs))
+	}
+	out := baseTx.Outs[0]
+	if out.AssetID() != testChainIDs.AvaxAssetID || out.Out.Amount() != change {
+		t.Fatalf("change output is %d of %s, expected %d of %s", out.Out.Amount(), out.AssetID(), change, testChainIDs.AvaxAssetID)
+	}
+	owners := out.Out.(*secp256k1fx.TransferOutput).OutputOwners
+	if owners.Threshold != 1 || len(owners.Addrs) != 1 || owners.Addrs[0] != fee.PrivateKey.PublicKey().Address() {
+		t.Fatal("change output isn't owned by the fee key")
+	}
+}
@@ -0,0 +1,515 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txhelper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+
+	avalancheconstants "github.com/ava-labs/avalanchego/utils/constants"
+)
+
+// PChainTxBuilder builds P Chain transactions and signs them with its keys, without the keystore
+// The transactions spend the unlocked AVAX UTXOs given to the builder, each UTXO is spent at most once
+// and the change goes back to the first key
+// Unsigned transactions, e.g. malformed on purpose, can be signed with Sign
+type PChainTxBuilder struct {
+	chainIDs      constants.ChainIDs
+	txFee         uint64
+	creationTxFee uint64
+	keys          map[ids.ShortID]*crypto.PrivateKeySECP256K1R
+	changeAddr    ids.ShortID
+	// utxos are the UTXOs that can be spent, keyed by input ID, atomicUTXOs the ones that can be imported
+	utxos       map[ids.ID]*avax.UTXO
+	atomicUTXOs map[ids.ID]*avax.UTXO
+	spent       map[ids.ID]bool
+	// subnetOwners are the control keys of the subnets, needed to sign the subnet transactions
+	subnetOwners map[ids.ID]*secp256k1fx.OutputOwners
+}
+
+// NewPChainTxBuilder creates a builder of P Chain transactions of the network [chainIDs] signed by [keys]
+func NewPChainTxBuilder(chainIDs constants.ChainIDs, txFee uint64, keys ...*crypto.PrivateKeySECP256K1R) *PChainTxBuilder {
+	builder := &PChainTxBuilder{
+		chainIDs:      chainIDs,
+		txFee:         txFee,
+		creationTxFee: txFee,
+		keys:          map[ids.ShortID]*crypto.PrivateKeySECP256K1R{},
+		utxos:         map[ids.ID]*avax.UTXO{},
+		atomicUTXOs:   map[ids.ID]*avax.UTXO{},
+		spent:         map[ids.ID]bool{},
+		subnetOwners:  map[ids.ID]*secp256k1fx.OutputOwners{},
+	}
+	for i, key := range keys {
+		address := key.PublicKey().Address()
+		builder.keys[address] = key
+		if i == 0 {
+			builder.changeAddr = address
+		}
+	}
+	return builder
+}
+
+// CreationTxFee sets the fee of the CreateSubnet and CreateChain transactions, the tx fee by default
+func (b *PChainTxBuilder) CreationTxFee(fee uint64) *PChainTxBuilder {
+	b.creationTxFee = fee
+	return b
+}
+
+// UTXOs adds [utxos] to the UTXOs the builder can spend
+func (b *PChainTxBuilder) UTXOs(utxos ...*avax.UTXO) *PChainTxBuilder {
+	for _, utxo := range utxos {
+		b.utxos[utxo.InputID()] = utxo
+	}
+	return b
+}
+
+// AtomicUTXOs adds [utxos], exported to the P Chain, to the UTXOs the builder can import
+func (b *PChainTxBuilder) AtomicUTXOs(utxos ...*avax.UTXO) *PChainTxBuilder {
+	for _, utxo := range utxos {
+		b.atomicUTXOs[utxo.InputID()] = utxo
+	}
+	return b
+}
+
+// SubnetOwners sets the control keys of [subnetID], the subnets created by the builder are added automatically
+func (b *PChainTxBuilder) SubnetOwners(subnetID ids.ID, owners *secp256k1fx.OutputOwners) *PChainTxBuilder {
+	b.subnetOwners[subnetID] = owners
+	return b
+}
+
+// FetchUTXOs adds the UTXOs of the builder keys, and the ones exported to them from [sourceChain] (e.g. X), read from [client]
+func (b *PChainTxBuilder) FetchUTXOs(client *avalanchegoclient.Client, sourceChain string) error {
+	var addresses []string
+	for address := range b.keys {
+		formattedAddress, err := formatting.FormatAddress("P", avalancheconstants.GetHRP(b.chainIDs.NetworkID), address.Bytes())
+		if err != nil {
+			return fmt.Errorf("couldn't format address %s: %w", address, err)
+		}
+		addresses = append(addresses, formattedAddress)
+	}
+
+	utxosBytes, _, err := client.PChainAPI().GetUTXOs(addresses, 0, "", "")
+	if err != nil {
+		return fmt.Errorf("couldn't get the UTXOs: %w", err)
+	}
+	utxos, err := parsePChainUTXOs(utxosBytes)
+	if err != nil {
+		return err
+	}
+	b.UTXOs(utxos...)
+
+	atomicUTXOsBytes, _, err := client.PChainAPI().GetAtomicUTXOs(addresses, sourceChain, 0, "", "")
+	if err != nil {
+		return fmt.Errorf("couldn't get the atomic UTXOs: %w", err)
+	}
+	atomicUTXOs, err := parsePChainUTXOs(atomicUTXOsBytes)
+	if err != nil {
+		return err
+	}
+	b.AtomicUTXOs(atomicUTXOs...)
+	return nil
+}
+
+// ImportTx returns a transaction importing every atomic UTXO from [sourceChainID] to [to], minus the tx fee
+func (b *PChainTxBuilder) ImportTx(sourceChainID ids.ID, to ids.ShortID) (*platformvm.Tx, error) {
+	var importedInputs []*avax.TransferableInput
+	var importedAmount uint64
+	for inputID, utxo := range b.atomicUTXOs {
+		if b.spent[inputID] {
+			continue
+		}
+		in, err := b.spendUTXO(utxo)
+		if err != nil {
+			continue
+		}
+		importedInputs = append(importedInputs, in)
+		importedAmount += in.In.Amount()
+	}
+	if importedAmount < b.txFee {
+		return nil, fmt.Errorf("imported amount %d can't pay the tx fee %d", importedAmount, b.txFee)
+	}
+	avax.SortTransferableInputs(importedInputs)
+
+	var outs []*avax.TransferableOutput
+	if importedAmount > b.txFee {
+		outs = append(outs, b.output(importedAmount-b.txFee, to))
+	}
+
+	return b.signAndSpend(&platformvm.UnsignedImportTx{
+		BaseTx:         b.baseTx(nil, outs),
+		SourceChain:    sourceChainID,
+		ImportedInputs: importedInputs,
+	}, importedInputs, outs)
+}
+
+// ExportTx returns a transaction exporting [amount] to the address [to] of [destinationChainID]
+func (b *PChainTxBuilder) ExportTx(destinationChainID ids.ID, amount uint64, to ids.ShortID) (*platformvm.Tx, error) {
+	ins, outs, err := b.spend(amount + b.txFee)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.signAndSpend(&platformvm.UnsignedExportTx{
+		BaseTx:           b.baseTx(ins, outs),
+		DestinationChain: destinationChainID,
+		ExportedOutputs:  []*avax.TransferableOutput{b.output(amount, to)},
+	}, ins, outs)
+}
+
+// AddValidatorTx returns a transaction staking [stake] for [nodeID] to validate the primary network from [start] to [end]
+// the stake and the rewards go to [rewardAddr], [shares] is the delegation fee, times 10,000
+func (b *PChainTxBuilder) AddValidatorTx(nodeID ids.ShortID, stake uint64, start, end time.Time, rewardAddr ids.ShortID, shares uint32) (*platformvm.Tx, error) {
+	ins, outs, err := b.spend(stake)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.signAndSpend(&platformvm.UnsignedAddValidatorTx{
+		BaseTx:       b.baseTx(ins, outs),
+		Validator:    validator(nodeID, stake, start, end),
+		Stake:        []*avax.TransferableOutput{b.output(stake, rewardAddr)},
+		RewardsOwner: owners(rewardAddr),
+		Shares:       shares,
+	}, ins, outs)
+}
+
+// AddDelegatorTx returns a transaction delegating [stake] to [nodeID] from [start] to [end]
+// the stake and the rewards go to [rewardAddr]
+func (b *PChainTxBuilder) AddDelegatorTx(nodeID ids.ShortID, stake uint64, start, end time.Time, rewardAddr ids.ShortID) (*platformvm.Tx, error) {
+	ins, outs, err := b.spend(stake)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.signAndSpend(&platformvm.UnsignedAddDelegatorTx{
+		BaseTx:       b.baseTx(ins, outs),
+		Validator:    validator(nodeID, stake, start, end),
+		Stake:        []*avax.TransferableOutput{b.output(stake, rewardAddr)},
+		RewardsOwner: owners(rewardAddr),
+	}, ins, outs)
+}
+
+// CreateSubnetTx returns a transaction creating a subnet controlled by [subnetOwners]
+// the subnet ID is the ID of the transaction
+func (b *PChainTxBuilder) CreateSubnetTx(subnetOwners *secp256k1fx.OutputOwners) (*platformvm.Tx, error) {
+	ins, outs, err := b.spend(b.creationTxFee)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := b.signAndSpend(&platformvm.UnsignedCreateSubnetTx{
+		BaseTx: b.baseTx(ins, outs),
+		Owner:  subnetOwners,
+	}, ins, outs)
+	if err != nil {
+		return nil, err
+	}
+	b.SubnetOwners(tx.ID(), subnetOwners)
+	return tx, nil
+}
+
+// AddSubnetValidatorTx returns a transaction adding [nodeID] as a validator of [subnetID], with [weight], from [start] to [end]
+func (b *PChainTxBuilder) AddSubnetValidatorTx(subnetID ids.ID, nodeID ids.ShortID, weight uint64, start, end time.Time) (*platformvm.Tx, error) {
+	subnetAuth, err := b.subnetAuth(subnetID)
+	if err != nil {
+		return nil, err
+	}
+	ins, outs, err := b.spend(b.txFee)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.signAndSpend(&platformvm.UnsignedAddSubnetValidatorTx{
+		BaseTx: b.baseTx(ins, outs),
+		Validator: platformvm.SubnetValidator{
+			Validator: validator(nodeID, weight, start, end),
+			Subnet:    subnetID,
+		},
+		SubnetAuth: subnetAuth,
+	}, ins, outs)
+}
+
+// CreateChainTx returns a transaction creating the blockchain [name] of [subnetID], running [vmID] and [fxIDs]
+// from [genesis], the blockchain ID is the ID of the transaction
+func (b *PChainTxBuilder) CreateChainTx(subnetID ids.ID, name string, vmID ids.ID, fxIDs []ids.ID, genesis []byte) (*platformvm.Tx, error) {
+	subnetAuth, err := b.subnetAuth(subnetID)
+	if err != nil {
+		return nil, err
+	}
+	ins, outs, err := b.spend(b.creationTxFee)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.signAndSpend(&platformvm.UnsignedCreateChainTx{
+		BaseTx:      b.baseTx(ins, outs),
+		SubnetID:    subnetID,
+		ChainName:   name,
+		VMID:        vmID,
+		FxIDs:       fxIDs,
+		GenesisData: genesis,
+		SubnetAuth:  subnetAuth,
+	}, ins, outs)
+}
+
+// Sign signs [unsignedTx] with the builder keys: one credential per input, the regular inputs then the imported
+// ones, and one for the subnet authorization of the subnet transactions
+// The inputs must spend UTXOs given to the builder
+func (b *PChainTxBuilder) Sign(unsignedTx platformvm.UnsignedTx) (*platformvm.Tx, error) {
+	var ins []*avax.TransferableInput
+	var subnetID ids.ID
+	var subnetAuth verify.Verifiable
+	switch utx := unsignedTx.(type) {
+	case *platformvm.UnsignedImportTx:
+		ins = append(append(ins, utx.Ins...), utx.ImportedInputs...)
+	case *platformvm.UnsignedExportTx:
+		ins = utx.Ins
+	case *platformvm.UnsignedAddValidatorTx:
+		ins = utx.Ins
+	case *platformvm.UnsignedAddDelegatorTx:
+		ins = utx.Ins
+	case *platformvm.UnsignedCreateSubnetTx:
+		ins = utx.Ins
+	case *platformvm.UnsignedAddSubnetValidatorTx:
+		ins, subnetID, subnetAuth = utx.Ins, utx.Validator.Subnet, utx.SubnetAuth
+	case *platformvm.UnsignedCreateChainTx:
+		ins, subnetID, subnetAuth = utx.Ins, utx.SubnetID, utx.SubnetAuth
+	default:
+		return nil, fmt.Errorf("unsupported P Chain transaction %T", unsignedTx)
+	}
+
+	signers := make([][]*crypto.PrivateKeySECP256K1R, 0, len(ins)+1)
+	for _, in := range ins {
+		utxo, ok := b.utxos[in.InputID()]
+		if !ok {
+			utxo, ok = b.atomicUTXOs[in.InputID()]
+		}
+		if !ok {
+			return nil, fmt.Errorf("input %s doesn't spend a UTXO of the builder", in.InputID())
+		}
+		out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+		if !ok {
+			return nil, fmt.Errorf("UTXO %s is not a transfer output", in.InputID())
+		}
+		input, ok := in.In.(*secp256k1fx.TransferInput)
+		if !ok {
+			return nil, fmt.Errorf("input %s is not a transfer input", in.InputID())
+		}
+		keys, err := b.signingKeys(&out.OutputOwners, input.SigIndices)
+		if err != nil {
+			return nil, fmt.Errorf("can't sign input %s: %w", in.InputID(), err)
+		}
+		signers = append(signers, keys)
+	}
+
+	if subnetAuth != nil {
+		input, ok := subnetAuth.(*secp256k1fx.Input)
+		if !ok {
+			return nil, fmt.Errorf("subnet authorization of subnet %s is not an input", subnetID)
+		}
+		subnetOwners, ok := b.subnetOwners[subnetID]
+		if !ok {
+			return nil, fmt.Errorf("owners of subnet %s are unknown", subnetID)
+		}
+		keys, err := b.signingKeys(subnetOwners, input.SigIndices)
+		if err != nil {
+			return nil, fmt.Errorf("can't sign the authorization of subnet %s: %w", subnetID, err)
+		}
+		signers = append(signers, keys)
+	}
+
+	tx := &platformvm.Tx{UnsignedTx: unsignedTx}
+	if err := tx.Sign(platformvm.Codec, signers); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Issue issues [tx] to the P Chain of [client] and returns its ID
+func (b *PChainTxBuilder) Issue(client *avalanchegoclient.Client, tx *platformvm.Tx) (ids.ID, error) {
+	return client.PChainAPI().IssueTx(tx.Bytes())
+}
+
+// signAndSpend signs [unsignedTx], marks the UTXOs of [ins] spent and adds its [outs] (the change) to the
+// UTXOs the builder can spend, so that transactions can be chained before being accepted
+func (b *PChainTxBuilder) signAndSpend(unsignedTx platformvm.UnsignedTx, ins []*avax.TransferableInput, outs []*avax.TransferableOutput) (*platformvm.Tx, error) {
+	tx, err := b.Sign(unsignedTx)
+	if err != nil {
+		return nil, err
+	}
+	b.markSpent(ins)
+	for i, out := range outs {
+		b.UTXOs(&avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: tx.ID(), OutputIndex: uint32(i)},
+			Asset:  out.Asset,
+			Out:    out.Out,
+		})
+	}
+	return tx, nil
+}
+
+func (b *PChainTxBuilder) markSpent(ins []*avax.TransferableInput) {
+	for _, in := range ins {
+		b.spent[in.InputID()] = true
+	}
+}
+
+func (b *PChainTxBuilder) baseTx(ins []*avax.TransferableInput, outs []*avax.TransferableOutput) platformvm.BaseTx {
+	avax.SortTransferableInputs(ins)
+	avax.SortTransferableOutputs(outs, platformvm.Codec)
+	return platformvm.BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    b.chainIDs.NetworkID,
+		BlockchainID: b.chainIDs.PlatformChainID,
+		Ins:          ins,
+		Outs:         outs,
+	}}
+}
+
+// spend returns the inputs spending at least [amount] of the builder UTXOs, and the change output if any
+func (b *PChainTxBuilder) spend(amount uint64) ([]*avax.TransferableInput, []*avax.TransferableOutput, error) {
+	var ins []*avax.TransferableInput
+	var spentAmount uint64
+	for inputID, utxo := range b.utxos {
+		if spentAmount >= amount {
+			break
+		}
+		if b.spent[inputID] || utxo.AssetID() != b.chainIDs.AvaxAssetID {
+			continue
+		}
+		in, err := b.spendUTXO(utxo)
+		if err != nil {
+			continue
+		}
+		ins = append(ins, in)
+		spentAmount += in.In.Amount()
+	}
+	if spentAmount < amount {
+		return nil, nil, fmt.Errorf("the builder UTXOs hold %d, %d is needed", spentAmount, amount)
+	}
+
+	var outs []*avax.TransferableOutput
+	if spentAmount > amount {
+		outs = append(outs, b.output(spentAmount-amount, b.changeAddr))
+	}
+	return ins, outs, nil
+}
+
+// spendUTXO returns the input spending the unlocked AVAX [utxo], if the builder keys reach its threshold
+func (b *PChainTxBuilder) spendUTXO(utxo *avax.UTXO) (*avax.TransferableInput, error) {
+	out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+	if !ok {
+		return nil, fmt.Errorf("UTXO %s is not a transfer output", utxo.InputID())
+	}
+	if out.Locktime > uint64(time.Now().Unix()) {
+		return nil, fmt.Errorf("UTXO %s is locked", utxo.InputID())
+	}
+
+	var sigIndices []uint32
+	for i, address := range out.Addrs {
+		if uint32(len(sigIndices)) == out.Threshold {
+			break
+		}
+		if _, ok := b.keys[address]; ok {
+			sigIndices = append(sigIndices, uint32(i))
+		}
+	}
+	if uint32(len(sigIndices)) != out.Threshold {
+		return nil, fmt.Errorf("the builder keys don't reach the threshold of UTXO %s", utxo.InputID())
+	}
+
+	return &avax.TransferableInput{
+		UTXOID: utxo.UTXOID,
+		Asset:  utxo.Asset,
+		In: &secp256k1fx.TransferInput{
+			Amt:   out.Amt,
+			Input: secp256k1fx.Input{SigIndices: sigIndices},
+		},
+	}, nil
+}
+
+// signingKeys returns the builder keys of the [owners] addresses at [sigIndices]
+func (b *PChainTxBuilder) signingKeys(owners *secp256k1fx.OutputOwners, sigIndices []uint32) ([]*crypto.PrivateKeySECP256K1R, error) {
+	keys := make([]*crypto.PrivateKeySECP256K1R, 0, len(sigIndices))
+	for _, sigIndex := range sigIndices {
+		if int(sigIndex) >= len(owners.Addrs) {
+			return nil, fmt.Errorf("signature index %d is out of the %d owners", sigIndex, len(owners.Addrs))
+		}
+		key, ok := b.keys[owners.Addrs[sigIndex]]
+		if !ok {
+			return nil, fmt.Errorf("the builder has no key of owner %s", owners.Addrs[sigIndex])
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// subnetAuth returns the authorization signed by the builder keys controlling [subnetID]
+func (b *PChainTxBuilder) subnetAuth(subnetID ids.ID) (*secp256k1fx.Input, error) {
+	subnetOwners, ok := b.subnetOwners[subnetID]
+	if !ok {
+		return nil, fmt.Errorf("owners of subnet %s are unknown", subnetID)
+	}
+
+	input := &secp256k1fx.Input{}
+	for i, address := range subnetOwners.Addrs {
+		if uint32(len(input.SigIndices)) == subnetOwners.Threshold {
+			break
+		}
+		if _, ok := b.keys[address]; ok {
+			input.SigIndices = append(input.SigIndices, uint32(i))
+		}
+	}
+	if uint32(len(input.SigIndices)) != subnetOwners.Threshold {
+		return nil, fmt.Errorf("the builder keys don't reach the threshold of subnet %s", subnetID)
+	}
+	return input, nil
+}
+
+func (b *PChainTxBuilder) output(amount uint64, to ids.ShortID) *avax.TransferableOutput {
+	return &avax.TransferableOutput{
+		Asset: avax.Asset{ID: b.chainIDs.AvaxAssetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt:          amount,
+			OutputOwners: *owners(to),
+		},
+	}
+}
+
+func owners(address ids.ShortID) *secp256k1fx.OutputOwners {
+	return &secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{address},
+	}
+}
+
+func validator(nodeID ids.ShortID, weight uint64, start, end time.Time) platformvm.Validator {
+	return platformvm.Validator{
+		NodeID: nodeID,
+		Start:  uint64(start.Unix()),
+		End:    uint64(end.Unix()),
+		Wght:   weight,
+	}
+}
+
+func parsePChainUTXOs(utxosBytes [][]byte) ([]*avax.UTXO, error) {
+	utxos := make([]*avax.UTXO, 0, len(utxosBytes))
+	for _, utxoBytes := range utxosBytes {
+		utxo := &avax.UTXO{}
+		if _, err := platformvm.Codec.Unmarshal(utxoBytes, utxo); err != nil {
+			return nil, fmt.Errorf("couldn't parse UTXO: %w", err)
+		}
+		utxos = append(utxos, utxo)
+	}
+	return utxos, nil
+}
@@ -0,0 +1,408 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txhelper
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+const (
+	testCreationTxFee = 2000
+	testStake         = 4000
+)
+
+// pChainTestEnv is a builder with its keys and the UTXOs it was given
+type pChainTestEnv struct {
+	builder    *PChainTxBuilder
+	changeKey  *crypto.PrivateKeySECP256K1R
+	otherKey   *crypto.PrivateKeySECP256K1R
+	subnetKeys []*crypto.PrivateKeySECP256K1R
+	subnetID   ids.ID
+	utxos      map[ids.ID]*avax.UTXO
+}
+
+// newPChainTestEnv returns a builder holding a UTXO of each of [amounts], owned by its second key, plus a locked
+// UTXO and a UTXO of another asset it must not spend, and the owners of a 2 of 3 subnet
+func newPChainTestEnv(t *testing.T, amounts ...uint64) *pChainTestEnv {
+	t.Helper()
+
+	env := &pChainTestEnv{
+		changeKey:  newTestKey(t),
+		otherKey:   newTestKey(t),
+		subnetKeys: []*crypto.PrivateKeySECP256K1R{newTestKey(t), newTestKey(t)},
+		subnetID:   ids.GenerateTestID(),
+		utxos:      map[ids.ID]*avax.UTXO{},
+	}
+	env.builder = NewPChainTxBuilder(testChainIDs, testTxFee, append([]*crypto.PrivateKeySECP256K1R{env.changeKey, env.otherKey}, env.subnetKeys...)...).
+		CreationTxFee(testCreationTxFee).
+		SubnetOwners(env.subnetID, ownedBy(2, append(env.subnetKeys, newTestKey(t))...))
+
+	for _, amount := range amounts {
+		env.addUTXO(testChainIDs.AvaxAssetID, &secp256k1fx.TransferOutput{Amt: amount, OutputOwners: *ownedBy(1, env.otherKey)})
+	}
+	lockedOwners := ownedBy(1, env.otherKey)
+	lockedOwners.Locktime = uint64(time.Now().Add(time.Hour).Unix())
+	env.addUTXO(testChainIDs.AvaxAssetID, &secp256k1fx.TransferOutput{Amt: testStake * 10, OutputOwners: *lockedOwners})
+	env.addUTXO(ids.GenerateTestID(), &secp256k1fx.TransferOutput{Amt: testStake * 10, OutputOwners: *ownedBy(1, env.otherKey)})
+	return env
+}
+
+func (env *pChainTestEnv) addUTXO(assetID ids.ID, out *secp256k1fx.TransferOutput) {
+	utxo := newTestUTXO(assetID, out)
+	env.utxos[utxo.InputID()] = utxo
+	env.builder.UTXOs(utxo)
+}
+
+func TestPChainTxBuilder(t *testing.T) {
+	start := time.Now().Add(time.Minute)
+	end := start.Add(24 * time.Hour)
+	nodeID := ids.GenerateTestShortID()
+	rewardAddr := ids.GenerateTestShortID()
+
+	tests := []struct {
+		name string
+		// amounts are the amounts of the UTXOs given to the builder
+		amounts []uint64
+		create  func(env *pChainTestEnv) (*platformvm.Tx, error)
+		// consumed is the amount the tx moves out of the builder UTXOs, besides the fee
+		consumed uint64
+		fee      uint64
+		// verify runs the syntactic verification of the unsigned tx
+		verify func(ctx *snow.Context, utx platformvm.UnsignedTx) error
+	}{
+		{
+			name:    "add validator with change",
+			amounts: []uint64{3 * testStake / 4, 3 * testStake / 4},
+			create: func(env *pChainTestEnv) (*platformvm.Tx, error) {
+				return env.builder.AddValidatorTx(nodeID, testStake, start, end, rewardAddr, 20000)
+			},
+			consumed: testStake,
+			verify: func(ctx *snow.Context, utx platformvm.UnsignedTx) error {
+				return utx.(*platformvm.UnsignedAddValidatorTx).Verify(ctx, platformvm.Codec, testStake, testStake, 0, math.MaxInt64, 0)
+			},
+		},
+		{
+			name:    "add delegator without change",
+			amounts: []uint64{testStake},
+			create: func(env *pChainTestEnv) (*platformvm.Tx, error) {
+				return env.builder.AddDelegatorTx(nodeID, testStake, start, end, rewardAddr)
+			},
+			consumed: testStake,
+			verify: func(ctx *snow.Context, utx platformvm.UnsignedTx) error {
+				return utx.(*platformvm.UnsignedAddDelegatorTx).Verify(ctx, platformvm.Codec, testStake, 0, math.MaxInt64)
+			},
+		},
+		{
+			name:    "create subnet",
+			amounts: []uint64{testStake},
+			create: func(env *pChainTestEnv) (*platformvm.Tx, error) {
+				return env.builder.CreateSubnetTx(ownedBy(1, env.changeKey))
+			},
+			fee: testCreationTxFee,
+			verify: func(ctx *snow.Context, utx platformvm.UnsignedTx) error {
+				return utx.(*platformvm.UnsignedCreateSubnetTx).Verify(ctx, platformvm.Codec, testCreationTxFee, testChainIDs.AvaxAssetID)
+			},
+		},
+		{
+			name:    "add subnet validator",
+			amounts: []uint64{testStake},
+			create: func(env *pChainTestEnv) (*platformvm.Tx, error) {
+				return env.builder.AddSubnetValidatorTx(env.subnetID, nodeID, 10, start, end)
+			},
+			fee: testTxFee,
+			verify: func(ctx *snow.Context, utx platformvm.UnsignedTx) error {
+				return utx.(*platformvm.UnsignedAddSubnetValidatorTx).Verify(ctx, platformvm.Codec, testTxFee, testChainIDs.AvaxAssetID, 0, math.MaxInt64)
+			},
+		},
+		{
+			name:    "create chain",
+			amounts: []uint64{testCreationTxFee},
+			create: func(env *pChainTestEnv) (*platformvm.Tx, error) {
+				return env.builder.CreateChainTx(env.subnetID, "chain", ids.GenerateTestID(), nil, []byte("genesis"))
+			},
+			fee: testCreationTxFee,
+			verify: func(ctx *snow.Context, utx platformvm.UnsignedTx) error {
+				return utx.(*platformvm.UnsignedCreateChainTx).Verify(ctx, platformvm.Codec, testCreationTxFee, testChainIDs.AvaxAssetID)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newPChainTestEnv(t, test.amounts...)
+			tx, err := test.create(env)
+			if err != nil {
+				t.Fatalf("failed to create the tx: %v", err)
+			}
+
+			checkPChainTxBytes(t, tx)
+			ins := checkPChainBalance(t, env, tx, test.consumed, test.fee)
+			checkPChainCredentials(t, env, tx, ins)
+
+			ctx := snow.DefaultContextTest()
+			ctx.NetworkID = testChainIDs.NetworkID
+			ctx.ChainID = testChainIDs.PlatformChainID
+			ctx.AVAXAssetID = testChainIDs.AvaxAssetID
+			if err := test.verify(ctx, tx.UnsignedTx); err != nil {
+				t.Fatalf("tx isn't valid: %v", err)
+			}
+		})
+	}
+}
+
+func TestPChainTxBuilderStakeOutputs(t *testing.T) {
+	env := newPChainTestEnv(t, testStake)
+	nodeID := ids.GenerateTestShortID()
+	rewardAddr := ids.GenerateTestShortID()
+	start := time.Now().Add(time.Minute)
+	end := start.Add(24 * time.Hour)
+
+	tx, err := env.builder.AddValidatorTx(nodeID, testStake, start, end, rewardAddr, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utx := tx.UnsignedTx.(*platformvm.UnsignedAddValidatorTx)
+	if utx.Validator.NodeID != nodeID || utx.Validator.Wght != testStake ||
+		utx.Validator.Start != uint64(start.Unix()) || utx.Validator.End != uint64(end.Unix()) {
+		t.Fatalf("unexpected validator %+v", utx.Validator)
+	}
+	if len(utx.Stake) != 1 || utx.Stake[0].Output().Amount() != testStake || !isOwnedBy(utx.Stake[0].Out, rewardAddr) {
+		t.Fatal("stake isn't a single output to the reward address")
+	}
+	rewardsOwner, ok := utx.RewardsOwner.(*secp256k1fx.OutputOwners)
+	if !ok || rewardsOwner.Threshold != 1 || len(rewardsOwner.Addrs) != 1 || rewardsOwner.Addrs[0] != rewardAddr {
+		t.Fatal("rewards don't go to the reward address")
+	}
+}
+
+func TestPChainTxBuilderChaining(t *testing.T) {
+	env := newPChainTestEnv(t, 2*testCreationTxFee+testTxFee)
+
+	subnetOwners := ownedBy(1, env.changeKey)
+	subnetTx, err := env.builder.CreateSubnetTx(subnetOwners)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the subnet owners are known to the builder, and its change is spent by the next txs
+	chainTx, err := env.builder.CreateChainTx(subnetTx.ID(), "chain", ids.GenerateTestID(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainIns := chainTx.UnsignedTx.(*platformvm.UnsignedCreateChainTx).Ins
+	if len(chainIns) != 1 || chainIns[0].TxID != subnetTx.ID() || chainIns[0].In.Amount() != testCreationTxFee+testTxFee {
+		t.Fatal("the create chain tx doesn't spend the change of the create subnet tx")
+	}
+	if len(chainTx.Creds) != 2 {
+		t.Fatalf("create chain tx has %d credentials, expected an input and a subnet authorization", len(chainTx.Creds))
+	}
+
+	validatorTx, err := env.builder.AddSubnetValidatorTx(subnetTx.ID(), ids.GenerateTestShortID(), 10, time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	validatorIns := validatorTx.UnsignedTx.(*platformvm.UnsignedAddSubnetValidatorTx).Ins
+	if len(validatorIns) != 1 || validatorIns[0].TxID != chainTx.ID() {
+		t.Fatal("the add subnet validator tx doesn't spend the change of the create chain tx")
+	}
+	if outs := validatorTx.UnsignedTx.(*platformvm.UnsignedAddSubnetValidatorTx).Outs; len(outs) != 0 {
+		t.Fatalf("add subnet validator tx has %d outputs, expected no change", len(outs))
+	}
+
+	// every unlocked AVAX UTXO is spent
+	if _, err := env.builder.ExportTx(testChainIDs.XChainID, 1, env.changeKey.PublicKey().Address()); err == nil {
+		t.Fatal("expected the builder to have no UTXOs left")
+	}
+}
+
+func TestPChainTxBuilderErrors(t *testing.T) {
+	start := time.Now().Add(time.Minute)
+	end := start.Add(24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		create func(env *pChainTestEnv) (*platformvm.Tx, error)
+	}{
+		{
+			name: "stake above the unlocked UTXOs",
+			create: func(env *pChainTestEnv) (*platformvm.Tx, error) {
+				return env.builder.AddValidatorTx(ids.GenerateTestShortID(), testStake+1, start, end, ids.GenerateTestShortID(), 20000)
+			},
+		},
+		{
+			name: "creation fee above the UTXOs",
+			create: func(env *pChainTestEnv) (*platformvm.Tx, error) {
+				env.builder.CreationTxFee(testStake + 1)
+				return env.builder.CreateSubnetTx(ownedBy(1, env.changeKey))
+			},
+		},
+		{
+			name: "unknown subnet",
+			create: func(env *pChainTestEnv) (*platformvm.Tx, error) {
+				return env.builder.AddSubnetValidatorTx(ids.GenerateTestID(), ids.GenerateTestShortID(), 10, start, end)
+			},
+		},
+		{
+			name: "keys below the subnet threshold",
+			create: func(env *pChainTestEnv) (*platformvm.Tx, error) {
+				env.builder.SubnetOwners(env.subnetID, ownedBy(2, env.subnetKeys[0], newTestKey(t)))
+				return env.builder.CreateChainTx(env.subnetID, "chain", ids.GenerateTestID(), nil, nil)
+			},
+		},
+		{
+			name: "nothing to import",
+			create: func(env *pChainTestEnv) (*platformvm.Tx, error) {
+				return env.builder.ImportTx(testChainIDs.XChainID, env.changeKey.PublicKey().Address())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newPChainTestEnv(t, testStake)
+			if _, err := test.create(env); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+// checkPChainTxBytes checks the [tx] ID is the hash of its bytes, and the bytes parse back to the same tx
+func checkPChainTxBytes(t *testing.T, tx *platformvm.Tx) {
+	t.Helper()
+
+	if expected := ids.ID(hashing.ComputeHash256Array(tx.Bytes())); tx.ID() != expected {
+		t.Fatalf("tx ID is %s, expected the hash of its bytes %s", tx.ID(), expected)
+	}
+
+	parsed := platformvm.Tx{}
+	if _, err := platformvm.Codec.Unmarshal(tx.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse the tx bytes: %v", err)
+	}
+	reparsedBytes, err := platformvm.Codec.Marshal(0, &parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reparsedBytes, tx.Bytes()) {
+		t.Fatal("parsed tx bytes differ from the tx bytes")
+	}
+}
+
+// checkPChainBalance checks the inputs of [tx] spend unlocked AVAX UTXOs of the builder and cover [consumed]
+// plus [fee] exactly, the rest going back to the change key, and returns the inputs
+func checkPChainBalance(t *testing.T, env *pChainTestEnv, tx *platformvm.Tx, consumed, fee uint64) []*avax.TransferableInput {
+	t.Helper()
+
+	baseTx, _, _ := pChainTxParts(t, tx.UnsignedTx)
+	var inAmount uint64
+	for _, in := range baseTx.Ins {
+		utxo, ok := env.utxos[in.InputID()]
+		if !ok {
+			t.Fatalf("input %s doesn't spend a UTXO of the builder", in.InputID())
+		}
+		out := utxo.Out.(*secp256k1fx.TransferOutput)
+		if utxo.AssetID() != testChainIDs.AvaxAssetID || out.Locktime != 0 {
+			t.Fatalf("input %s spends a locked or non AVAX UTXO", in.InputID())
+		}
+		if in.In.Amount() != out.Amt {
+			t.Fatalf("input %s spends %d of UTXO holding %d", in.InputID(), in.In.Amount(), out.Amt)
+		}
+		inAmount += in.In.Amount()
+	}
+
+	var changeAmount uint64
+	for _, out := range baseTx.Outs {
+		if out.AssetID() != testChainIDs.AvaxAssetID || !isOwnedBy(out.Out, env.changeKey.PublicKey().Address()) {
+			t.Fatal("change output isn't AVAX owned by the change key")
+		}
+		changeAmount += out.Out.Amount()
+	}
+	if len(baseTx.Outs) > 1 {
+		t.Fatalf("tx has %d change outputs, expected at most 1", len(baseTx.Outs))
+	}
+
+	if inAmount != consumed+fee+changeAmount {
+		t.Fatalf("tx spends %d, moves %d, pays %d and gives back %d", inAmount, consumed, fee, changeAmount)
+	}
+	return baseTx.Ins
+}
+
+// checkPChainCredentials checks [tx] has a credential per input, plus one for the subnet authorization,
+// holding the signatures of the unsigned tx by the keys at the signature indices of the owners
+func checkPChainCredentials(t *testing.T, env *pChainTestEnv, tx *platformvm.Tx, ins []*avax.TransferableInput) {
+	t.Helper()
+
+	type signed struct {
+		owners     *secp256k1fx.OutputOwners
+		sigIndices []uint32
+	}
+	var expected []signed
+	for _, in := range ins {
+		owners := env.utxos[in.InputID()].Out.(*secp256k1fx.TransferOutput).OutputOwners
+		expected = append(expected, signed{owners: &owners, sigIndices: in.In.(*secp256k1fx.TransferInput).SigIndices})
+	}
+	if _, subnetID, subnetAuth := pChainTxParts(t, tx.UnsignedTx); subnetAuth != nil {
+		expected = append(expected, signed{owners: env.builder.subnetOwners[subnetID], sigIndices: subnetAuth.(*secp256k1fx.Input).SigIndices})
+	}
+
+	if len(tx.Creds) != len(expected) {
+		t.Fatalf("tx has %d credentials, expected %d", len(tx.Creds), len(expected))
+	}
+	hash := hashing.ComputeHash256(tx.UnsignedBytes())
+	factory := crypto.FactorySECP256K1R{}
+	for i, cred := range tx.Creds {
+		sigs := cred.(*secp256k1fx.Credential).Sigs
+		if len(sigs) != len(expected[i].sigIndices) {
+			t.Fatalf("credential %d has %d signatures, expected %d", i, len(sigs), len(expected[i].sigIndices))
+		}
+		for j, sig := range sigs {
+			publicKey, err := factory.RecoverHashPublicKey(hash, sig[:])
+			if err != nil {
+				t.Fatalf("failed to recover the signer of credential %d signature %d: %v", i, j, err)
+			}
+			if address := expected[i].owners.Addrs[expected[i].sigIndices[j]]; publicKey.Address() != address {
+				t.Fatalf("credential %d signature %d is by %s, expected %s", i, j, publicKey.Address(), address)
+			}
+		}
+	}
+}
+
+// pChainTxParts returns the base tx of [utx], and the subnet and its authorization for the subnet transactions
+func pChainTxParts(t *testing.T, utx platformvm.UnsignedTx) (platformvm.BaseTx, ids.ID, verify.Verifiable) {
+	t.Helper()
+
+	switch utx := utx.(type) {
+	case *platformvm.UnsignedAddValidatorTx:
+		return utx.BaseTx, ids.Empty, nil
+	case *platformvm.UnsignedAddDelegatorTx:
+		return utx.BaseTx, ids.Empty, nil
+	case *platformvm.UnsignedCreateSubnetTx:
+		return utx.BaseTx, ids.Empty, nil
+	case *platformvm.UnsignedAddSubnetValidatorTx:
+		return utx.BaseTx, utx.Validator.Subnet, utx.SubnetAuth
+	case *platformvm.UnsignedCreateChainTx:
+		return utx.BaseTx, utx.SubnetID, utx.SubnetAuth
+	default:
+		t.Fatalf("unexpected tx type %T", utx)
+		return platformvm.BaseTx{}, ids.Empty, nil
+	}
+}
+
+func isOwnedBy(out verify.State, address ids.ShortID) bool {
+	transferOut, ok := out.(*secp256k1fx.TransferOutput)
+	return ok && transferOut.Threshold == 1 && len(transferOut.Addrs) == 1 && transferOut.Addrs[0] == address
+}
@@ -16,11 +16,12 @@ import (
 )
 
 // CreateSingleUTXOTx returns a transaction spending an individual utxo owned by [privateKey]
-func CreateSingleUTXOTx(utxo *avax.UTXO, inputAmount, outputAmount uint64, address ids.ShortID, privateKey *crypto.PrivateKeySECP256K1R, codec codec.Manager) (*avm.Tx, error) {
+// on the X Chain of the network [chainIDs]
+func CreateSingleUTXOTx(chainIDs constants.ChainIDs, utxo *avax.UTXO, inputAmount, outputAmount uint64, address ids.ShortID, privateKey *crypto.PrivateKeySECP256K1R, codec codec.Manager) (*avm.Tx, error) {
 	keys := [][]*crypto.PrivateKeySECP256K1R{{privateKey}}
 	outs := []*avax.TransferableOutput{
 		{
-			Asset: avax.Asset{ID: constants.AvaxAssetID},
+			Asset: avax.Asset{ID: chainIDs.AvaxAssetID},
 			Out: &secp256k1fx.TransferOutput{
 				Amt: outputAmount,
 				OutputOwners: secp256k1fx.OutputOwners{
@@ -42,14 +43,14 @@ func CreateSingleUTXOTx(utxo *avax.UTXO, inputAmount, outputAmount uint64, addre
 	ins := []*avax.TransferableInput{
 		{
 			UTXOID: utxo.UTXOID,
-			Asset:  avax.Asset{ID: constants.AvaxAssetID},
+			Asset:  avax.Asset{ID: chainIDs.AvaxAssetID},
 			In:     transferableIn.(avax.TransferableIn),
 		},
 	}
 
 	tx := &avm.Tx{UnsignedTx: &avm.BaseTx{BaseTx: avax.BaseTx{
-		NetworkID:    constants.NetworkID,
-		BlockchainID: constants.XChainID,
+		NetworkID:    chainIDs.NetworkID,
+		BlockchainID: chainIDs.XChainID,
 		Outs:         outs,
 		Ins:          ins,
 	}}}
@@ -62,7 +63,7 @@ func CreateSingleUTXOTx(utxo *avax.UTXO, inputAmount, outputAmount uint64, addre
 
 // CreateConsecutiveTransactions returns a string of [numTxs] sending [utxo] back and forth
 // assumes that [privateKey] is the sole owner of [utxo]
-func CreateConsecutiveTransactions(utxo *avax.UTXO, numTxs, amount, txFee uint64, privateKey *crypto.PrivateKeySECP256K1R, codec codec.Manager) ([][]byte, []ids.ID, error) {
+func CreateConsecutiveTransactions(chainIDs constants.ChainIDs, utxo *avax.UTXO, numTxs, amount, txFee uint64, privateKey *crypto.PrivateKeySECP256K1R, codec codec.Manager) ([][]byte, []ids.ID, error) {
 	if numTxs*txFee > amount {
 		return nil, nil, fmt.Errorf("Insufficient starting funds to send %v transactions with a txFee of %v", numTxs, txFee)
 	}
@@ -74,7 +75,7 @@ func CreateConsecutiveTransactions(utxo *avax.UTXO, numTxs, amount, txFee uint64
 	inputAmount := amount
 	outputAmount := amount - txFee
 	for i := uint64(0); i < numTxs; i++ {
-		tx, err := CreateSingleUTXOTx(utxo, inputAmount, outputAmount, address, privateKey, codec)
+		tx, err := CreateSingleUTXOTx(chainIDs, utxo, inputAmount, outputAmount, address, privateKey, codec)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -91,7 +92,7 @@ func CreateConsecutiveTransactions(utxo *avax.UTXO, numTxs, amount, txFee uint64
 // CreateIndependentBurnTxs creates a list of transactions spending each utxo in [utxos] and sending [utxoAmount] - [txFee] back
 // to [privateKey]
 // Assumes that each utxo has a sufficient amount to pay the transaction fee.
-func CreateIndependentBurnTxs(utxos []*avax.UTXO, utxoAmount, txFee uint64, privateKey *crypto.PrivateKeySECP256K1R, codec codec.Manager) ([][]byte, []ids.ID, error) {
+func CreateIndependentBurnTxs(chainIDs constants.ChainIDs, utxos []*avax.UTXO, utxoAmount, txFee uint64, privateKey *crypto.PrivateKeySECP256K1R, codec codec.Manager) ([][]byte, []ids.ID, error) {
 	var txBytes [][]byte
 	var txIDs []ids.ID
 
@@ -99,7 +100,7 @@ func CreateIndependentBurnTxs(utxos []*avax.UTXO, utxoAmount, txFee uint64, priv
 	address := privateKey.PublicKey().Address()
 
 	for _, utxo := range utxos {
-		tx, err := CreateSingleUTXOTx(utxo, utxoAmount, utxoAmount-txFee, address, privateKey, codec)
+		tx, err := CreateSingleUTXOTx(chainIDs, utxo, utxoAmount, utxoAmount-txFee, address, privateKey, codec)
 		if err != nil {
 			return nil, nil, err
 		}
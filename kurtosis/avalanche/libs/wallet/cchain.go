@@ -0,0 +1,123 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wallet
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/txhelper"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palantir/stacktrace"
+)
+
+// CImport imports every UTXO exported to the Wallet keys from [sourceChainID] to the EthAddress of the Wallet
+// the C Chain doesn't charge a fee on imports
+func (w *Wallet) CImport(client *avalanchegoclient.Client, sourceChainID ids.ID) (ids.ID, error) {
+	addresses, err := w.formattedAddresses("C")
+	if err != nil {
+		return ids.Empty, err
+	}
+	utxosBytes, _, err := client.CChainAtomicAPI().GetAtomicUTXOs(addresses, sourceChainID.String(), 0, "", "")
+	if err != nil {
+		return ids.Empty, stacktrace.Propagate(err, "Failed to get the atomic UTXOs from %s", sourceChainID)
+	}
+	utxos, err := parseUTXOs(utxosBytes, evm.Codec)
+	if err != nil {
+		return ids.Empty, err
+	}
+
+	importedIns, signers, importedAmounts := w.spendAll(utxos)
+	if len(importedIns) == 0 {
+		return ids.Empty, stacktrace.NewError("The wallet has nothing to import from %s", sourceChainID)
+	}
+
+	var outs []evm.EVMOutput
+	for assetID, amount := range importedAmounts {
+		outs = append(outs, evm.EVMOutput{
+			Address: w.EthAddress,
+			Amount:  amount,
+			AssetID: assetID,
+		})
+	}
+	evm.SortEVMOutputs(outs)
+
+	tx := &evm.Tx{UnsignedTx: &evm.UnsignedImportTx{
+		NetworkID:      w.chainIDs.NetworkID,
+		BlockchainID:   w.chainIDs.CChainID,
+		SourceChain:    sourceChainID,
+		ImportedInputs: importedIns,
+		Outs:           outs,
+	}}
+	return w.issueCTx(client, tx, signers)
+}
+
+// CExport exports [amount] of AVAX from the EthAddress of the Wallet to the address [to] of [destinationChainID]
+// the tx fee is burned from the EthAddress balance
+func (w *Wallet) CExport(client *avalanchegoclient.Client, destinationChainID ids.ID, amount uint64, to ids.ShortID) (ids.ID, error) {
+	nonce, err := client.CChaiConcurrentEth().NonceAt(context.Background(), w.EthAddress, nil)
+	if err != nil {
+		return ids.Empty, stacktrace.Propagate(err, "Failed to get the nonce of %s", w.EthAddress.Hex())
+	}
+
+	tx := &evm.Tx{UnsignedTx: &evm.UnsignedExportTx{
+		NetworkID:        w.chainIDs.NetworkID,
+		BlockchainID:     w.chainIDs.CChainID,
+		DestinationChain: destinationChainID,
+		Ins: []evm.EVMInput{{
+			Address: w.EthAddress,
+			Amount:  amount + w.txFee,
+			AssetID: w.chainIDs.AvaxAssetID,
+			Nonce:   nonce,
+		}},
+		ExportedOutputs: []*avax.TransferableOutput{w.output(w.chainIDs.AvaxAssetID, amount, to)},
+	}}
+	return w.issueCTx(client, tx, [][]*crypto.PrivateKeySECP256K1R{{w.keys[0]}})
+}
+
+// CSend sends [amount] wei from the EthAddress of the Wallet to [to] and returns the hash of the EVM transaction
+func (w *Wallet) CSend(client *avalanchegoclient.Client, to common.Address, amount *big.Int) (common.Hash, error) {
+	ethClient := client.CChaiConcurrentEth()
+	ctx := context.Background()
+
+	chainID, err := ethClient.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, stacktrace.Propagate(err, "Failed to get the C Chain ID")
+	}
+	nonce, err := ethClient.NonceAt(ctx, w.EthAddress, nil)
+	if err != nil {
+		return common.Hash{}, stacktrace.Propagate(err, "Failed to get the nonce of %s", w.EthAddress.Hex())
+	}
+	gasPrice, err := ethClient.SuggestGasPrice(ctx)
+	if err != nil {
+		return common.Hash{}, stacktrace.Propagate(err, "Failed to get the gas price")
+	}
+
+	tx, err := txhelper.CreateEthTx(chainID, nonce, txhelper.EthCall{To: &to, Value: amount, Gas: txhelper.EthTransferGas}, gasPrice, w.keys[0])
+	if err != nil {
+		return common.Hash{}, stacktrace.Propagate(err, "Failed to create the EVM transaction")
+	}
+	if err := ethClient.SendTransaction(ctx, tx); err != nil {
+		client.RecordTx(avalanchegoclient.CChain, "", avalanchegoclient.TxIssueFailed)
+		return common.Hash{}, stacktrace.Propagate(err, "Failed to send EVM transaction %s", tx.Hash().Hex())
+	}
+	return tx.Hash(), nil
+}
+
+func (w *Wallet) issueCTx(client *avalanchegoclient.Client, tx *evm.Tx, signers [][]*crypto.PrivateKeySECP256K1R) (ids.ID, error) {
+	if err := tx.Sign(evm.Codec, signers); err != nil {
+		return ids.Empty, stacktrace.Propagate(err, "Failed to sign the C Chain transaction")
+	}
+	txID, err := client.CChainAPI().IssueTx(tx.Bytes())
+	if err != nil {
+		client.RecordTx(avalanchegoclient.CChain, "", avalanchegoclient.TxIssueFailed)
+		return ids.Empty, stacktrace.Propagate(err, "Failed to issue C Chain transaction %s", tx.ID())
+	}
+	return txID, nil
+}
@@ -0,0 +1,78 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wallet
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/txhelper"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/palantir/stacktrace"
+)
+
+// PChainTxBuilder returns a P Chain transaction builder signing with the Wallet keys, loaded with their UTXOs
+// and the ones exported to them from [sourceChainID], read from [client]
+func (w *Wallet) PChainTxBuilder(client *avalanchegoclient.Client, sourceChainID ids.ID) (*txhelper.PChainTxBuilder, error) {
+	builder := txhelper.NewPChainTxBuilder(w.chainIDs, w.txFee, w.keys...)
+	if err := builder.FetchUTXOs(client, sourceChainID.String()); err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to fetch the P Chain UTXOs")
+	}
+	return builder, nil
+}
+
+// PImport imports every UTXO exported to the Wallet keys from [sourceChainID], minus the tx fee
+func (w *Wallet) PImport(client *avalanchegoclient.Client, sourceChainID ids.ID) (ids.ID, error) {
+	builder, err := w.PChainTxBuilder(client, sourceChainID)
+	if err != nil {
+		return ids.Empty, err
+	}
+	tx, err := builder.ImportTx(sourceChainID, w.Address())
+	return w.issuePTx(client, builder, tx, err)
+}
+
+// PExport exports [amount] of AVAX to the address [to] of [destinationChainID]
+func (w *Wallet) PExport(client *avalanchegoclient.Client, destinationChainID ids.ID, amount uint64, to ids.ShortID) (ids.ID, error) {
+	builder, err := w.PChainTxBuilder(client, w.chainIDs.XChainID)
+	if err != nil {
+		return ids.Empty, err
+	}
+	tx, err := builder.ExportTx(destinationChainID, amount, to)
+	return w.issuePTx(client, builder, tx, err)
+}
+
+// PAddValidator stakes [stake] for [nodeID] to validate the primary network from [start] to [end]
+// the stake and the rewards go back to the Wallet
+func (w *Wallet) PAddValidator(client *avalanchegoclient.Client, nodeID ids.ShortID, stake uint64, start, end time.Time, shares uint32) (ids.ID, error) {
+	builder, err := w.PChainTxBuilder(client, w.chainIDs.XChainID)
+	if err != nil {
+		return ids.Empty, err
+	}
+	tx, err := builder.AddValidatorTx(nodeID, stake, start, end, w.Address(), shares)
+	return w.issuePTx(client, builder, tx, err)
+}
+
+// PAddDelegator delegates [stake] to [nodeID] from [start] to [end]
+// the stake and the rewards go back to the Wallet
+func (w *Wallet) PAddDelegator(client *avalanchegoclient.Client, nodeID ids.ShortID, stake uint64, start, end time.Time) (ids.ID, error) {
+	builder, err := w.PChainTxBuilder(client, w.chainIDs.XChainID)
+	if err != nil {
+		return ids.Empty, err
+	}
+	tx, err := builder.AddDelegatorTx(nodeID, stake, start, end, w.Address())
+	return w.issuePTx(client, builder, tx, err)
+}
+
+func (w *Wallet) issuePTx(client *avalanchegoclient.Client, builder *txhelper.PChainTxBuilder, tx *platformvm.Tx, err error) (ids.ID, error) {
+	if err != nil {
+		return ids.Empty, stacktrace.Propagate(err, "Failed to build the P Chain transaction")
+	}
+	txID, err := builder.Issue(client, tx)
+	if err != nil {
+		client.RecordTx(avalanchegoclient.PChain, "", avalanchegoclient.TxIssueFailed)
+		return ids.Empty, stacktrace.Propagate(err, "Failed to issue P Chain transaction %s", tx.ID())
+	}
+	return txID, nil
+}
@@ -0,0 +1,119 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wallet
+
+import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/formatters"
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palantir/stacktrace"
+
+	helpers "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/codecs"
+	avalancheconstants "github.com/ava-labs/avalanchego/utils/constants"
+)
+
+// Wallet holds private keys on the client side, and spends their funds on the X, P and C Chains through
+// the client of any node, without a keystore user
+// The first key receives the change and the imported funds
+type Wallet struct {
+	chainIDs constants.ChainIDs
+	txFee    uint64
+	keys     []*crypto.PrivateKeySECP256K1R
+	xCodec   codec.Manager
+
+	XAddress string
+	PAddress string
+	// CAddress is the bech32 address holding the UTXOs exported to the C Chain, EthAddress the account they are imported to
+	CAddress   string
+	EthAddress common.Address
+}
+
+// New creates a Wallet of the network [chainIDs] holding [keys], paying [txFee] per transaction
+func New(chainIDs constants.ChainIDs, txFee uint64, keys ...*crypto.PrivateKeySECP256K1R) (*Wallet, error) {
+	if len(keys) == 0 {
+		return nil, stacktrace.NewError("A wallet needs at least one key")
+	}
+
+	xCodec, err := helpers.CreateXChainCodec()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to create the X Chain codec")
+	}
+
+	w := &Wallet{
+		chainIDs:   chainIDs,
+		txFee:      txFee,
+		keys:       keys,
+		xCodec:     xCodec,
+		EthAddress: evm.GetEthAddress(keys[0]),
+	}
+	if w.XAddress, err = w.formatAddress("X", keys[0].PublicKey().Address()); err != nil {
+		return nil, err
+	}
+	if w.PAddress, err = w.formatAddress("P", keys[0].PublicKey().Address()); err != nil {
+		return nil, err
+	}
+	if w.CAddress, err = w.formatAddress("C", keys[0].PublicKey().Address()); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// NewFromFormattedKeys creates a Wallet holding the [formattedKeys] (PrivateKey-...), e.g. the funded key of the genesis
+func NewFromFormattedKeys(chainIDs constants.ChainIDs, txFee uint64, formattedKeys ...string) (*Wallet, error) {
+	var keys []*crypto.PrivateKeySECP256K1R
+	for _, formattedKey := range formattedKeys {
+		key, err := formatters.ConvertFormattedPrivateKey(formattedKey)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to parse the private key")
+		}
+		keys = append(keys, key)
+	}
+	return New(chainIDs, txFee, keys...)
+}
+
+// Keys returns the private keys of the Wallet
+func (w *Wallet) Keys() []*crypto.PrivateKeySECP256K1R {
+	return w.keys
+}
+
+// Address returns the address of the first key, receiving the change and the imported funds
+func (w *Wallet) Address() ids.ShortID {
+	return w.keys[0].PublicKey().Address()
+}
+
+// formattedAddresses returns the addresses of every key on [chain]
+func (w *Wallet) formattedAddresses(chain string) ([]string, error) {
+	addresses := make([]string, 0, len(w.keys))
+	for _, key := range w.keys {
+		address, err := w.formatAddress(chain, key.PublicKey().Address())
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+func (w *Wallet) formatAddress(chain string, address ids.ShortID) (string, error) {
+	formattedAddress, err := formatting.FormatAddress(chain, avalancheconstants.GetHRP(w.chainIDs.NetworkID), address.Bytes())
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Failed to format address %s", address)
+	}
+	return formattedAddress, nil
+}
+
+// key returns the key of [address], if the Wallet holds it
+func (w *Wallet) key(address ids.ShortID) (*crypto.PrivateKeySECP256K1R, bool) {
+	for _, key := range w.keys {
+		if key.PublicKey().Address() == address {
+			return key, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,243 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wallet
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/palantir/stacktrace"
+)
+
+// XUTXOs returns the X Chain UTXOs of the Wallet keys, read from [client]
+func (w *Wallet) XUTXOs(client *avalanchegoclient.Client) ([]*avax.UTXO, error) {
+	addresses, err := w.formattedAddresses("X")
+	if err != nil {
+		return nil, err
+	}
+	utxosBytes, _, err := client.XChainAPI().GetUTXOs(addresses, 0, "", "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to get the X Chain UTXOs")
+	}
+	return parseUTXOs(utxosBytes, w.xCodec)
+}
+
+// XSend sends [amount] of [assetID] to the X Chain address [to] and returns the ID of the issued transaction
+func (w *Wallet) XSend(client *avalanchegoclient.Client, assetID ids.ID, amount uint64, to ids.ShortID) (ids.ID, error) {
+	utxos, err := w.XUTXOs(client)
+	if err != nil {
+		return ids.Empty, err
+	}
+
+	amounts := map[ids.ID]uint64{assetID: amount}
+	amounts[w.chainIDs.AvaxAssetID] += w.txFee
+	ins, signers, outs, err := w.spend(utxos, amounts, w.xCodec)
+	if err != nil {
+		return ids.Empty, err
+	}
+	outs = append(outs, w.output(assetID, amount, to))
+	avax.SortTransferableOutputs(outs, w.xCodec)
+
+	tx := &avm.Tx{UnsignedTx: &avm.BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    w.chainIDs.NetworkID,
+		BlockchainID: w.chainIDs.XChainID,
+		Outs:         outs,
+		Ins:          ins,
+	}}}
+	return w.issueXTx(client, tx, signers)
+}
+
+// XExport exports [amount] of AVAX to the address [to] of [destinationChainID] (the P or C Chain)
+func (w *Wallet) XExport(client *avalanchegoclient.Client, destinationChainID ids.ID, amount uint64, to ids.ShortID) (ids.ID, error) {
+	utxos, err := w.XUTXOs(client)
+	if err != nil {
+		return ids.Empty, err
+	}
+
+	ins, signers, outs, err := w.spend(utxos, map[ids.ID]uint64{w.chainIDs.AvaxAssetID: amount + w.txFee}, w.xCodec)
+	if err != nil {
+		return ids.Empty, err
+	}
+
+	tx := &avm.Tx{UnsignedTx: &avm.ExportTx{
+		BaseTx: avm.BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    w.chainIDs.NetworkID,
+			BlockchainID: w.chainIDs.XChainID,
+			Outs:         outs,
+			Ins:          ins,
+		}},
+		DestinationChain: destinationChainID,
+		ExportedOuts:     []*avax.TransferableOutput{w.output(w.chainIDs.AvaxAssetID, amount, to)},
+	}}
+	return w.issueXTx(client, tx, signers)
+}
+
+// XImport imports every UTXO exported to the Wallet keys from [sourceChainID] (the P or C Chain), minus the tx fee
+func (w *Wallet) XImport(client *avalanchegoclient.Client, sourceChainID ids.ID) (ids.ID, error) {
+	addresses, err := w.formattedAddresses("X")
+	if err != nil {
+		return ids.Empty, err
+	}
+	utxosBytes, _, err := client.XChainAPI().GetAtomicUTXOs(addresses, sourceChainID.String(), 0, "", "")
+	if err != nil {
+		return ids.Empty, stacktrace.Propagate(err, "Failed to get the atomic UTXOs from %s", sourceChainID)
+	}
+	utxos, err := parseUTXOs(utxosBytes, w.xCodec)
+	if err != nil {
+		return ids.Empty, err
+	}
+
+	importedIns, signers, importedAmounts := w.spendAll(utxos)
+	if importedAmounts[w.chainIDs.AvaxAssetID] < w.txFee {
+		return ids.Empty, stacktrace.NewError("The imported AVAX can't pay the tx fee %d", w.txFee)
+	}
+	importedAmounts[w.chainIDs.AvaxAssetID] -= w.txFee
+
+	var outs []*avax.TransferableOutput
+	for assetID, amount := range importedAmounts {
+		if amount > 0 {
+			outs = append(outs, w.output(assetID, amount, w.Address()))
+		}
+	}
+	avax.SortTransferableOutputs(outs, w.xCodec)
+
+	tx := &avm.Tx{UnsignedTx: &avm.ImportTx{
+		BaseTx: avm.BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    w.chainIDs.NetworkID,
+			BlockchainID: w.chainIDs.XChainID,
+			Outs:         outs,
+		}},
+		SourceChain: sourceChainID,
+		ImportedIns: importedIns,
+	}}
+	return w.issueXTx(client, tx, signers)
+}
+
+func (w *Wallet) issueXTx(client *avalanchegoclient.Client, tx *avm.Tx, signers [][]*crypto.PrivateKeySECP256K1R) (ids.ID, error) {
+	if err := tx.SignSECP256K1Fx(w.xCodec, signers); err != nil {
+		return ids.Empty, stacktrace.Propagate(err, "Failed to sign the X Chain transaction")
+	}
+	txID, err := client.XChainAPI().IssueTx(tx.Bytes())
+	if err != nil {
+		client.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+		return ids.Empty, stacktrace.Propagate(err, "Failed to issue X Chain transaction %s", tx.ID())
+	}
+	return txID, nil
+}
+
+// spend returns the sorted inputs spending at least [amounts] of each asset from [utxos], their signers and
+// the change outputs
+func (w *Wallet) spend(utxos []*avax.UTXO, amounts map[ids.ID]uint64, c codec.Manager) ([]*avax.TransferableInput, [][]*crypto.PrivateKeySECP256K1R, []*avax.TransferableOutput, error) {
+	var ins []*avax.TransferableInput
+	var signers [][]*crypto.PrivateKeySECP256K1R
+	spentAmounts := map[ids.ID]uint64{}
+	for _, utxo := range utxos {
+		assetID := utxo.AssetID()
+		if spentAmounts[assetID] >= amounts[assetID] {
+			continue
+		}
+		in, keys, ok := w.spendUTXO(utxo)
+		if !ok {
+			continue
+		}
+		ins = append(ins, in)
+		signers = append(signers, keys)
+		spentAmounts[assetID] += in.In.Amount()
+	}
+
+	var outs []*avax.TransferableOutput
+	for assetID, amount := range amounts {
+		if spentAmounts[assetID] < amount {
+			return nil, nil, nil, stacktrace.NewError("The wallet holds %d of asset %s, %d is needed", spentAmounts[assetID], assetID, amount)
+		}
+		if change := spentAmounts[assetID] - amount; change > 0 {
+			outs = append(outs, w.output(assetID, change, w.Address()))
+		}
+	}
+	avax.SortTransferableInputsWithSigners(ins, signers)
+	avax.SortTransferableOutputs(outs, c)
+	return ins, signers, outs, nil
+}
+
+// spendAll returns the sorted inputs spending every [utxos] the Wallet can spend, their signers and the spent amount of each asset
+func (w *Wallet) spendAll(utxos []*avax.UTXO) ([]*avax.TransferableInput, [][]*crypto.PrivateKeySECP256K1R, map[ids.ID]uint64) {
+	var ins []*avax.TransferableInput
+	var signers [][]*crypto.PrivateKeySECP256K1R
+	amounts := map[ids.ID]uint64{}
+	for _, utxo := range utxos {
+		in, keys, ok := w.spendUTXO(utxo)
+		if !ok {
+			continue
+		}
+		ins = append(ins, in)
+		signers = append(signers, keys)
+		amounts[utxo.AssetID()] += in.In.Amount()
+	}
+	avax.SortTransferableInputsWithSigners(ins, signers)
+	return ins, signers, amounts
+}
+
+// spendUTXO returns the input spending the unlocked transfer output of [utxo] and its signers
+// if the Wallet keys reach its threshold
+func (w *Wallet) spendUTXO(utxo *avax.UTXO) (*avax.TransferableInput, []*crypto.PrivateKeySECP256K1R, bool) {
+	out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+	if !ok || out.Locktime > uint64(time.Now().Unix()) {
+		return nil, nil, false
+	}
+
+	var sigIndices []uint32
+	var keys []*crypto.PrivateKeySECP256K1R
+	for i, address := range out.Addrs {
+		if uint32(len(keys)) == out.Threshold {
+			break
+		}
+		if key, ok := w.key(address); ok {
+			sigIndices = append(sigIndices, uint32(i))
+			keys = append(keys, key)
+		}
+	}
+	if uint32(len(keys)) != out.Threshold {
+		return nil, nil, false
+	}
+
+	return &avax.TransferableInput{
+		UTXOID: utxo.UTXOID,
+		Asset:  utxo.Asset,
+		In: &secp256k1fx.TransferInput{
+			Amt:   out.Amt,
+			Input: secp256k1fx.Input{SigIndices: sigIndices},
+		},
+	}, keys, true
+}
+
+func (w *Wallet) output(assetID ids.ID, amount uint64, to ids.ShortID) *avax.TransferableOutput {
+	return &avax.TransferableOutput{
+		Asset: avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: amount,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{to},
+			},
+		},
+	}
+}
+
+func parseUTXOs(utxosBytes [][]byte, c codec.Manager) ([]*avax.UTXO, error) {
+	utxos := make([]*avax.UTXO, 0, len(utxosBytes))
+	for _, utxoBytes := range utxosBytes {
+		utxo := &avax.UTXO{}
+		if _, err := c.Unmarshal(utxoBytes, utxo); err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to parse UTXO")
+		}
+		utxos = append(utxos, utxo)
+	}
+	return utxos, nil
+}
@@ -0,0 +1,91 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+const (
+	assetCreatorNodeName  = "bootstrapNode-1"
+	assetReceiverNodeName = "bootstrapNode-2"
+	fixedCapSupply        = 1000
+	fixedCapTransfer      = 400
+	variableCapMint       = 500
+)
+
+// Assets creates a fixed cap and a multisig variable cap asset, mints and transfers them between two
+// nodes and verifies the balances of each asset
+func Assets(avalancheImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork := scenarios.NewBootStrappingNodeNetwork(avalancheImage)
+
+	test := func(network networks.Network) error {
+		topology := top.New(network).
+			AddNode(assetCreatorNodeName, testconstants.StakerUsername, testconstants.StakerPassword).
+			AddNode(assetReceiverNodeName, testconstants.DelegatorUsername, testconstants.DelegatorPassword).
+			AddGenesis(assetCreatorNodeName, testconstants.GenesisUsername, testconstants.GenesisPassword)
+		creator := topology.Node(assetCreatorNodeName)
+		receiver := topology.Node(assetReceiverNodeName)
+		// the AVAX pays the transaction fees
+		topology.Genesis().FundXChainAddresses([]string{creator.XAddress, receiver.XAddress}, testconstants.TotalAmount)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+
+		topology.AddFixedCapAsset("fixed", "FIX", 0, assetCreatorNodeName, map[string]uint64{creator.XAddress: fixedCapSupply})
+		topology.Asset("fixed").
+			Transfer(creator, receiver, fixedCapTransfer).
+			CheckBalance(creator, fixedCapSupply-fixedCapTransfer).
+			CheckBalance(receiver, fixedCapTransfer)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to create and transfer the fixed cap asset")
+		}
+		logrus.Infof("Verified the fixed cap asset balances after a transfer.")
+
+		// the creator controls both addresses of the first minter set, the receiver only one of the second one
+		creatorSecondAddress, err := creator.TryCreateXAddress()
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to create the second creator address")
+		}
+		topology.AddVariableCapAsset("variable", "VAR", 0, assetCreatorNodeName,
+			top.MinterSet{Threshold: 2, Minters: []string{creator.XAddress, creatorSecondAddress}},
+			top.MinterSet{Threshold: 2, Minters: []string{creator.XAddress, receiver.XAddress}},
+		)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to create the variable cap asset")
+		}
+
+		variableAsset := topology.Asset("variable")
+		if err := variableAsset.TryMint(receiver, receiver.XAddress, variableCapMint); err == nil {
+			return stacktrace.NewError("Node %s minted without reaching a minter set threshold", assetReceiverNodeName)
+		}
+		variableAsset.Mint(creator, receiver, variableCapMint)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to mint the variable cap asset")
+		}
+
+		// the mint was accepted by the creator node, the receiver node may not have accepted it yet
+		err = chainhelper.XChain().AwaitBalances(receiver.GetClient(), receiver.XAddress, map[string]uint64{
+			topology.Asset("fixed").AssetID.String(): fixedCapTransfer,
+			variableAsset.AssetID.String():           variableCapMint,
+		}, constants.TimeoutDuration)
+		if err != nil {
+			return stacktrace.Propagate(err, "Unexpected asset balances")
+		}
+		logrus.Infof("Verified the multisig minting and the balances of each asset.")
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
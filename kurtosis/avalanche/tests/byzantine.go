@@ -0,0 +1,112 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/byzantine"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/consistency"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/wallet"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+const (
+	byzantineNode     = "bootstrapNode-5"
+	conflictingTxsNum = 4
+)
+
+// ByzantineConflicts has a Byzantine node issue conflicting transactions spending the same UTXO to different nodes
+// at the same time, then replay them, and verifies exactly one of them is accepted on every node
+func ByzantineConflicts(avalancheImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork := scenarios.NewBootStrappingNodeNetwork(avalancheImage)
+	definedNetwork.Nodes[byzantineNode].Byzantine(true)
+
+	test := func(network networks.Network) error {
+		txFee := testconstants.TxFee
+		avalancheNetwork := networksavalanche.Cast(network)
+		client, err := avalancheNetwork.GetNodeClient(byzantineNode)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to fetch the client of %s", byzantineNode)
+		}
+
+		topology := top.New(network).
+			AddWallet("genesis", txFee, avalancheNetwork.GetGenesisConfig().FundedAddresses.PrivateKey)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+		chainIDs := topology.GetChainIDs()
+
+		factory := crypto.FactorySECP256K1R{}
+		key, err := factory.NewPrivateKey()
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to generate the key of the Byzantine wallet")
+		}
+		byzantineWallet, err := wallet.New(chainIDs, txFee, key.(*crypto.PrivateKeySECP256K1R))
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to create the Byzantine wallet")
+		}
+
+		// the Byzantine wallet gets a single UTXO, spent by every transaction of the conflict set
+		genesisWallet, err := topology.TryWallet("genesis")
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get the genesis wallet")
+		}
+		sendTxID, err := genesisWallet.XSend(client, chainIDs.AvaxAssetID, testconstants.SeedAmount, byzantineWallet.Address())
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to fund the Byzantine wallet")
+		}
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(client, sendTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept the funding tx: %s", sendTxID)
+		}
+		utxos, err := byzantineWallet.XUTXOs(client)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get the UTXOs of the Byzantine wallet")
+		}
+		if len(utxos) != 1 {
+			return stacktrace.NewError("The Byzantine wallet has %d UTXOs, expected the funding one", len(utxos))
+		}
+
+		adversary, err := byzantine.NewAdversary(definedNetwork, network)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to create the adversary")
+		}
+		set, err := adversary.ConflictingTxs(utxos[0], byzantineWallet.Keys()[0], byzantineWallet.Address(), conflictingTxsNum)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to create the conflicting transactions")
+		}
+		if err := adversary.IssueConflicting(set); err != nil {
+			return stacktrace.Propagate(err, "Failed to issue the conflicting transactions")
+		}
+		acceptedTxID, err := adversary.VerifyConflictSet(set)
+		if err != nil {
+			return stacktrace.Propagate(err, "The conflicting transactions were not decided consistently")
+		}
+
+		adversary.Replay(set)
+		replayedTxID, err := adversary.VerifyConflictSet(set)
+		if err != nil {
+			return stacktrace.Propagate(err, "The replayed transactions were not decided consistently")
+		}
+		if replayedTxID != acceptedTxID {
+			return stacktrace.NewError("Replaying the conflict set accepted %s instead of %s", replayedTxID, acceptedTxID)
+		}
+
+		if err := consistency.NewFromNetwork(network).XChainAddresses(byzantineWallet.XAddress).AwaitConsistency(constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "The nodes disagree on the Byzantine wallet balance")
+		}
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
@@ -0,0 +1,115 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/loadgen"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/formatters"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/txhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+const (
+	loadAccounts      = 10
+	loadTxsPerAccount = 20
+	counterDeployGas  = 100000
+	counterCallGas    = 100000
+)
+
+// counterBytecode deploys a contract incrementing the counter in its storage slot 0 on every call
+var counterBytecode = common.FromHex("600a600c600039600a6000f3" + "60016000540160005500")
+
+// CChainLoad deploys a counter contract and calls it from accounts spread over the five stakers at a target rate,
+// then verifies every call was mined and counted, reporting the receipt latency, the gas and the block rate
+func CChainLoad(avalancheImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork := scenarios.NewBootStrappingNodeNetwork(avalancheImage)
+
+	test := func(network networks.Network) error {
+		avalancheNetwork := networksavalanche.Cast(network)
+		var clients []*avalanchegoclient.Client
+		for i := 1; i <= len(definedNetwork.Nodes); i++ {
+			client, err := avalancheNetwork.GetNodeClient(fmt.Sprintf("bootstrapNode-%d", i))
+			if err != nil {
+				return stacktrace.Propagate(err, "Failed to fetch the client of bootstrapNode-%d", i)
+			}
+			clients = append(clients, client)
+		}
+
+		topology := top.New(network).
+			AddGenesis("bootstrapNode-1", testconstants.GenesisUsername, testconstants.GenesisPassword)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+
+		// the genesis key is funded on the C Chain by the genesis
+		genesisKey, err := formatters.ConvertFormattedPrivateKey(avalancheNetwork.GetGenesisConfig().FundedAddresses.PrivateKey)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to parse the genesis key")
+		}
+		counter, _, err := chainhelper.CChain().DeployContract(clients[0], genesisKey, counterBytecode, counterDeployGas, constants.TimeoutDuration)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to deploy the counter contract")
+		}
+
+		generator, err := loadgen.NewCChainLoadGenerator(clients, loadgen.CChainLoadConfig{
+			Accounts:      loadAccounts,
+			TxsPerAccount: loadTxsPerAccount,
+			Amount:        10 * units.Avax,
+			Call:          txhelper.EthCall{To: &counter, Gas: counterCallGas},
+			TargetTPS:     loadTargetTPS,
+		})
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to create the load generator")
+		}
+		testReport := avalancheNetwork.TestReport()
+		if err := testReport.Step("prepare", func() error { return generator.Prepare(topology.Genesis()) }); err != nil {
+			return stacktrace.Propagate(err, "Failed to prepare the load")
+		}
+
+		var report *loadgen.EthReport
+		if err := testReport.Step("run", func() error {
+			report, err = generator.Run()
+			return err
+		}); err != nil {
+			return stacktrace.Propagate(err, "Failed to run the load")
+		}
+		report.Log()
+		testReport.AddTxs(report.Name, report.TxsReport())
+		if !report.Succeeded() {
+			return stacktrace.NewError("%d of the %d calls were mined", report.Accepted, loadAccounts*loadTxsPerAccount)
+		}
+
+		// the receipts were polled on the node of each account, the counter is read once clients[1] has every block
+		if err := chainhelper.CChain().AwaitBlockNumber(clients[1], report.LastTxBlock, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to sync the blocks of the calls")
+		}
+		count, err := clients[1].CChaiConcurrentEth().StorageAt(context.Background(), counter, common.Hash{}, nil)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to read the counter")
+		}
+		if new(big.Int).SetBytes(count).Int64() != loadAccounts*loadTxsPerAccount {
+			return stacktrace.NewError("The counter is %d, expected %d", new(big.Int).SetBytes(count), loadAccounts*loadTxsPerAccount)
+		}
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
@@ -0,0 +1,81 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	customNetworkID  = 4242
+	customNumStakers = 3
+	customXAmount    = testconstants.TotalAmount
+)
+
+// CustomGenesis starts a network from its own genesis, with generated stakers, and verifies every node runs
+// the custom network, with the funded key X Chain allocation and the genesis stakers as the current validators
+func CustomGenesis(avalancheImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork, err := scenarios.NewCustomGenesisNetwork(avalancheImage, customNetworkID, customNumStakers, customXAmount)
+	if err != nil {
+		panic(stacktrace.Propagate(err, "Unable to create the custom genesis network"))
+	}
+
+	test := func(network networks.Network) error {
+		genesisConfig, err := definedNetwork.GetGenesisConfig()
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get the custom genesis")
+		}
+		var expectedStakers []string
+		for _, staker := range genesisConfig.Stakers {
+			expectedStakers = append(expectedStakers, staker.NodeID)
+		}
+		sort.Strings(expectedStakers)
+
+		for nodeID, client := range networksavalanche.Cast(network).GetNodeClients() {
+			networkID, err := client.InfoAPI().GetNetworkID()
+			if err != nil {
+				return stacktrace.Propagate(err, "Failed to get the network ID of %s", nodeID)
+			}
+			if networkID != customNetworkID {
+				return stacktrace.NewError("Node %s runs network %d, expected %d", nodeID, networkID, customNetworkID)
+			}
+
+			if err := chainhelper.XChain().CheckBalance(client, genesisConfig.FundedAddresses.Address,
+				genesisConfig.ChainIDs.AvaxAssetID.String(), customXAmount); err != nil {
+				return stacktrace.Propagate(err, "Node %s doesn't have the X Chain allocation of the genesis", nodeID)
+			}
+
+			currentValidators, err := client.PChainAPI().GetCurrentValidators(ids.Empty)
+			if err != nil {
+				return stacktrace.Propagate(err, "Failed to get the current validators of %s", nodeID)
+			}
+			var validators []string
+			for _, validatorIntf := range currentValidators {
+				validator := validatorIntf.(map[string]interface{})
+				validators = append(validators, fmt.Sprint(validator["nodeID"]))
+			}
+			sort.Strings(validators)
+			if strings.Join(validators, ",") != strings.Join(expectedStakers, ",") {
+				return stacktrace.NewError("Node %s has the validators %v, expected the genesis stakers %v", nodeID, validators, expectedStakers)
+			}
+		}
+		logrus.Infof("Verified the nodes run network %d with the allocations and stakers of its genesis.", customNetworkID)
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
@@ -0,0 +1,40 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology/topologyhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+// DefinedNetwork launches a network loaded from a network definition file
+// and verifies every node is up and connected to the others
+func DefinedNetwork(definedNetwork *networkbuilder.Network) *runner.AvalancheTestRunner {
+
+	test := func(network networks.Network) error {
+		topology, err := top.New(network).
+			LoadDefinedNetwork(definedNetwork).
+			Build()
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology of the defined network")
+		}
+
+		allNodes := topology.GetAllNodes()
+		if err := topologyhelper.VerifyConnectedPeers(allNodes, allNodes); err != nil {
+			return stacktrace.Propagate(err, "Nodes of the defined network are not connected to each other")
+		}
+
+		logrus.Infof("Verified the %d nodes of the defined network are connected.", len(allNodes))
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
@@ -0,0 +1,109 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+const (
+	firstDuplicateNodeName  = "firstDuplicateNode"
+	secondDuplicateNodeName = "secondDuplicateNode"
+	duplicateGenesisName    = "bootstrapNode-1"
+	// duplicateConnectDuration is how long the second node has to try to connect to the network
+	duplicateConnectDuration = 30 * time.Second
+)
+
+// DuplicateNodeID launches a second node with the staking cert, and so the NodeID, of a running node
+// avalanchego keeps a single connection per NodeID: the stakers must stay connected to the first node,
+// refusing the connections of the second one, and the first node must keep accepting transactions
+func DuplicateNodeID(avalancheImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork, secondNode := scenarios.NewDuplicateNodeIDNetwork(avalancheImage, firstDuplicateNodeName, secondDuplicateNodeName)
+
+	test := func(network networks.Network) error {
+		seedAmount := testconstants.SeedAmount
+
+		topology := top.New(network).
+			AddNode(firstDuplicateNodeName, testconstants.StakerUsername, testconstants.StakerPassword).
+			AddGenesis(duplicateGenesisName, testconstants.GenesisUsername, testconstants.GenesisPassword)
+		topology.Genesis().FundXChainAddresses([]string{topology.Node(firstDuplicateNodeName).XAddress}, testconstants.TotalAmount)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+		firstNode := topology.Node(firstDuplicateNodeName)
+		firstIPAddress := firstNode.GetIPAddress()
+
+		// the second node may never be available, it's not waited on
+		avalancheNetwork := networksavalanche.Cast(network)
+		definedNetwork.AddNode(secondNode)
+		if _, _, err := avalancheNetwork.CreateNodeNoCheck(definedNetwork, secondNode); err != nil {
+			return stacktrace.Propagate(err, "Failed to launch node %s", secondDuplicateNodeName)
+		}
+		time.Sleep(duplicateConnectDuration)
+
+		for i := 1; i <= definedNetwork.GetNumBootstrapNodes(); i++ {
+			stakerName := fmt.Sprintf("bootstrapNode-%d", i)
+			client, err := avalancheNetwork.GetNodeClient(stakerName)
+			if err != nil {
+				return stacktrace.Propagate(err, "Failed to get the client of %s", stakerName)
+			}
+			if err := checkPeerIP(client, firstNode.NodeID, firstIPAddress); err != nil {
+				return stacktrace.Propagate(err, "Staker %s isn't connected to the first node", stakerName)
+			}
+		}
+		logrus.Infof("Verified the stakers kept their connection to the first node %s.", firstNode.NodeID)
+
+		client := firstNode.GetClient()
+		txID, err := client.XChainAPI().Send(firstNode.UserPass, nil, "", seedAmount, "AVAX", topology.Genesis().Address, "")
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to send AVAX from the first node")
+		}
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(client, txID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "The first node doesn't accept transactions with a duplicate running")
+		}
+		logrus.Infof("Verified the first node keeps working with a duplicate of its NodeID running.")
+
+		// the duplicate isn't part of the network, it's removed so it's left out of the consistency check
+		if err := avalancheNetwork.RemoveNode(definedNetwork, secondNode); err != nil {
+			return stacktrace.Propagate(err, "Failed to remove node %s", secondDuplicateNodeName)
+		}
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
+
+// checkPeerIP checks the peer [nodeID] of the node of [client] is connected from [ipAddress]
+func checkPeerIP(client *avalanchegoclient.Client, nodeID string, ipAddress string) error {
+	peers, err := client.InfoAPI().Peers()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to get the peers")
+	}
+	for _, peer := range peers {
+		if peer.ID != nodeID {
+			continue
+		}
+		if strings.HasPrefix(peer.IP, ipAddress+":") || strings.HasPrefix(peer.PublicIP, ipAddress+":") {
+			return nil
+		}
+		return stacktrace.NewError("Peer %s is connected from %s (public IP %s), expected %s", nodeID, peer.IP, peer.PublicIP, ipAddress)
+	}
+	return stacktrace.NewError("Peer %s is not connected", nodeID)
+}
@@ -0,0 +1,108 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+const (
+	minorityNodeName = "bootstrapNode-1"
+	// stallDuration is how long transactions must stay undecided while the network is partitioned
+	stallDuration = 30 * time.Second
+	// recoveryTimeout is how long the stalled transactions have to be accepted once the network is healed
+	recoveryTimeout = 2 * time.Minute
+)
+
+// Partition isolates two of the five stakers, so that with a quorum of three they can't decide anything, and
+// verifies the X and P Chain transactions they issue stall until the network is healed
+func Partition(avalancheImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork := scenarios.NewBootStrappingNodeNetwork(avalancheImage)
+
+	test := func(network networks.Network) error {
+		txFee := testconstants.TxFee
+		totalAmount := testconstants.TotalAmount
+		seedAmount := testconstants.SeedAmount
+
+		topology := top.New(network).
+			AddNode(minorityNodeName, testconstants.StakerUsername, testconstants.StakerPassword).
+			AddGenesis(minorityNodeName, testconstants.GenesisUsername, testconstants.GenesisPassword)
+		topology.Genesis().FundXChainAddresses([]string{topology.Node(minorityNodeName).XAddress}, totalAmount)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+
+		node := topology.Node(minorityNodeName)
+		client := node.GetClient()
+
+		// funds the P Chain before partitioning the network
+		exportTxID, err := client.XChainAPI().ExportAVAX(node.UserPass, nil, "", seedAmount+txFee, node.PAddress)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to export AVAX to pchainAddress %s", node.PAddress)
+		}
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(client, exportTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ExportTx: %s", exportTxID)
+		}
+		importTxID, err := client.PChainAPI().ImportAVAX(node.UserPass, nil, "", node.PAddress, topology.GetChainIDs().XChainID.String())
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to import AVAX to pchainAddress %s", node.PAddress)
+		}
+		if err := chainhelper.PChain().AwaitTransactionAcceptance(client, importTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ImportTx: %s", importTxID)
+		}
+
+		avalancheNetwork := networksavalanche.Cast(network)
+		if err := avalancheNetwork.Partition(map[string][]string{
+			"minority": {minorityNodeName, "bootstrapNode-2"},
+		}); err != nil {
+			return stacktrace.Propagate(err, "Failed to partition the network")
+		}
+
+		xTxID, err := client.XChainAPI().Send(node.UserPass, nil, "", seedAmount, "AVAX", topology.Genesis().Address, "")
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to issue the X Chain transaction")
+		}
+		pTxID, err := client.PChainAPI().ExportAVAX(node.UserPass, nil, "", node.XAddress, seedAmount-txFee)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to issue the P Chain transaction")
+		}
+
+		if err := chainhelper.XChain().AwaitTransactionStall(client, xTxID, stallDuration); err != nil {
+			return stacktrace.Propagate(err, "The X Chain didn't stall without a quorum")
+		}
+		if err := chainhelper.PChain().AwaitTransactionStall(client, pTxID, stallDuration); err != nil {
+			return stacktrace.Propagate(err, "The P Chain didn't stall without a quorum")
+		}
+		logrus.Infof("Verified the X and P Chains stall without a quorum.")
+
+		if err := avalancheNetwork.Heal(); err != nil {
+			return stacktrace.Propagate(err, "Failed to heal the network")
+		}
+
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(client, xTxID, recoveryTimeout); err != nil {
+			return stacktrace.Propagate(err, "The X Chain didn't recover after healing the network")
+		}
+		if err := chainhelper.PChain().AwaitTransactionAcceptance(client, pTxID, recoveryTimeout); err != nil {
+			return stacktrace.Propagate(err, "The P Chain didn't recover after healing the network")
+		}
+		logrus.Infof("Verified the X and P Chains recover after healing the network.")
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout).
+		PartitioningEnabled(true)
+}
@@ -0,0 +1,95 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testhelpers"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+const (
+	restartSenderNodeName = "bootstrapNode-1"
+	restartedNodeName     = "bootstrapNode-3"
+)
+
+// NodeRestart stops one of the five stakers, issues X Chain transactions to it while it's down,
+// then starts it again and verifies it rejoins the network, catches up with the transactions it missed
+// and can issue new ones
+func NodeRestart(avalancheImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork := scenarios.NewBootStrappingNodeNetwork(avalancheImage)
+
+	test := func(network networks.Network) error {
+		seedAmount := testconstants.SeedAmount
+
+		topology := top.New(network).
+			AddNode(restartSenderNodeName, testconstants.StakerUsername, testconstants.StakerPassword).
+			AddNode(restartedNodeName, testconstants.StakerUsername, testconstants.StakerPassword).
+			AddGenesis(restartSenderNodeName, testconstants.GenesisUsername, testconstants.GenesisPassword)
+		topology.Genesis().FundXChainAddresses([]string{
+			topology.Node(restartSenderNodeName).XAddress,
+			topology.Node(restartedNodeName).XAddress,
+		}, 2*seedAmount)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+
+		sender := topology.Node(restartSenderNodeName)
+		senderClient := sender.GetClient()
+		node := topology.Node(restartedNodeName)
+
+		avalancheNetwork := networksavalanche.Cast(network)
+		restartedNode := definedNetwork.Nodes[restartedNodeName]
+		if err := avalancheNetwork.StopNode(restartedNode); err != nil {
+			return stacktrace.Propagate(err, "Failed to stop node %s", restartedNodeName)
+		}
+
+		// the rest of the network keeps its quorum and accepts the transaction the stopped node misses
+		missedTxID, err := senderClient.XChainAPI().Send(sender.UserPass, nil, "", seedAmount, "AVAX", node.XAddress, "")
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to send AVAX to %s while it's stopped", node.XAddress)
+		}
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(senderClient, missedTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept the transaction without node %s", restartedNodeName)
+		}
+
+		if _, err := avalancheNetwork.StartNode(definedNetwork, restartedNode); err != nil {
+			return stacktrace.Propagate(err, "Failed to start node %s again", restartedNodeName)
+		}
+		if err := testhelpers.AwaitRejoin(avalancheNetwork, restartedNodeName, len(definedNetwork.Nodes)-1, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Node %s didn't rejoin the network", restartedNodeName)
+		}
+
+		// the node kept its database, so its keystore user, and catches up with the missed transaction
+		client := node.GetClient()
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(client, missedTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Node %s didn't catch up with the transaction it missed", restartedNodeName)
+		}
+		if err := chainhelper.XChain().CheckBalance(client, node.XAddress, "AVAX", 3*seedAmount); err != nil {
+			return stacktrace.Propagate(err, "Node %s has a wrong balance after restarting", restartedNodeName)
+		}
+
+		txID, err := client.XChainAPI().Send(node.UserPass, nil, "", seedAmount, "AVAX", sender.XAddress, "")
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to send AVAX from the restarted node")
+		}
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(senderClient, txID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "The transaction of the restarted node wasn't accepted")
+		}
+		logrus.Infof("Verified node %s rejoined the network and caught up after restarting.", restartedNodeName)
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
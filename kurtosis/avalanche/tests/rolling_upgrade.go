@@ -0,0 +1,75 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testhelpers"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+const upgradeSenderNodeName = "bootstrapNode-1"
+
+// RollingUpgrade starts the five stakers with [fromImage] and upgrades them to [toImage] one node at a time,
+// verifying after each step that the upgraded node rejoined the network and that every node accepts a new
+// X Chain transaction
+func RollingUpgrade(fromImage string, toImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork := scenarios.NewBootStrappingNodeNetwork(fromImage)
+
+	test := func(network networks.Network) error {
+		seedAmount := testconstants.SeedAmount
+
+		topology := top.New(network).
+			AddNode(upgradeSenderNodeName, testconstants.StakerUsername, testconstants.StakerPassword).
+			AddGenesis(upgradeSenderNodeName, testconstants.GenesisUsername, testconstants.GenesisPassword)
+		topology.Genesis().FundXChainAddresses([]string{topology.Node(upgradeSenderNodeName).XAddress}, testconstants.TotalAmount)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+		sender := topology.Node(upgradeSenderNodeName)
+
+		avalancheNetwork := networksavalanche.Cast(network)
+		check := func(upgradedNodeID string) error {
+			if err := testhelpers.AwaitRejoin(avalancheNetwork, upgradedNodeID, len(definedNetwork.Nodes)-1, constants.TimeoutDuration); err != nil {
+				return stacktrace.Propagate(err, "Node %s didn't rejoin the network", upgradedNodeID)
+			}
+
+			// the sender may have been upgraded, its client is the one of its current container
+			client, err := sender.TryGetClient()
+			if err != nil {
+				return stacktrace.Propagate(err, "Failed to get the client of the sender")
+			}
+			// the sender sends the AVAX to itself, so each step only costs the transaction fee
+			txID, err := client.XChainAPI().Send(sender.UserPass, nil, "", seedAmount, "AVAX", sender.XAddress, "")
+			if err != nil {
+				return stacktrace.Propagate(err, "Failed to issue the X Chain transaction")
+			}
+			for nodeID, nodeClient := range avalancheNetwork.GetNodeClients() {
+				if err := chainhelper.XChain().AwaitTransactionAcceptance(nodeClient, txID, constants.TimeoutDuration); err != nil {
+					return stacktrace.Propagate(err, "Transaction %s wasn't accepted by node %s", txID, nodeID)
+				}
+			}
+			logrus.Infof("Verified the network is live after upgrading node %s.", upgradedNodeID)
+			return nil
+		}
+
+		if err := avalancheNetwork.RollingUpgrade(definedNetwork, toImage, nil, check); err != nil {
+			return stacktrace.Propagate(err, "Failed to upgrade the network from %s to %s", fromImage, toImage)
+		}
+		logrus.Infof("Verified the network stayed live while it was upgraded from %s to %s.", fromImage, toImage)
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
@@ -0,0 +1,114 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/ava-labs/avalanchego/vms/timestampvm"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+const (
+	subnetName            = "subnet"
+	subnetBlockchainName  = "timestamp"
+	subnetControlNodeName = "bootstrapNode-1"
+)
+
+// subnetValidatorNames are the stakers validating the subnet, they're genesis stakers so they already validate
+// the primary network
+var subnetValidatorNames = []string{"bootstrapNode-2", "bootstrapNode-3"}
+
+// Subnet creates a subnet once the network runs, adds two of the stakers as its validators and restarts them
+// with its ID whitelisted, then verifies they validate it by bootstrapping a timestamp VM blockchain created on it
+// The validators are then restarted requiring the subnet to be bootstrapped, and must have bootstrapped
+// the blockchain as soon as they're available
+func Subnet(avalancheImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork := scenarios.NewBootStrappingNodeNetwork(avalancheImage)
+
+	test := func(network networks.Network) error {
+		txFee := testconstants.TxFee
+		seedAmount := testconstants.SeedAmount
+
+		topology := top.New(network)
+		topology.
+			AddNode(subnetControlNodeName, testconstants.StakerUsername, testconstants.StakerPassword).
+			AddGenesis(subnetControlNodeName, testconstants.GenesisUsername, testconstants.GenesisPassword)
+		for _, validatorName := range subnetValidatorNames {
+			topology.AddNode(validatorName, testconstants.StakerUsername, testconstants.StakerPassword)
+		}
+		topology.Genesis().FundXChainAddresses([]string{topology.Node(subnetControlNodeName).XAddress}, testconstants.TotalAmount)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+
+		// the control node pays the subnet transactions from its P Chain balance
+		controlNode := topology.Node(subnetControlNodeName)
+		client := controlNode.GetClient()
+		exportTxID, err := client.XChainAPI().ExportAVAX(controlNode.UserPass, nil, "", seedAmount+txFee, controlNode.PAddress)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to export AVAX to pchainAddress %s", controlNode.PAddress)
+		}
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(client, exportTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ExportTx: %s", exportTxID)
+		}
+		importTxID, err := client.PChainAPI().ImportAVAX(controlNode.UserPass, nil, "", controlNode.PAddress, topology.GetChainIDs().XChainID.String())
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to import AVAX to pchainAddress %s", controlNode.PAddress)
+		}
+		if err := chainhelper.PChain().AwaitTransactionAcceptance(client, importTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ImportTx: %s", importTxID)
+		}
+
+		topology.AddSubnet(subnetName, subnetControlNodeName)
+		subnet := topology.Subnet(subnetName)
+		for _, validatorName := range subnetValidatorNames {
+			subnet.AddValidator(topology.Node(validatorName))
+		}
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to create the subnet")
+		}
+
+		// the subnet ID is only known now, the validators are restarted to sync it
+		avalancheNetwork := networksavalanche.Cast(network)
+		if err := avalancheNetwork.WhitelistSubnet(definedNetwork, subnet.SubnetID.String(), subnetValidatorNames...); err != nil {
+			return stacktrace.Propagate(err, "Failed to whitelist subnet %s", subnet.SubnetID)
+		}
+
+		subnet.
+			CreateBlockchain(subnetBlockchainName, timestampvm.ID.String(), []byte("subnet")).
+			AwaitBlockchainBootstrapped(subnetBlockchainName, constants.TimeoutDuration)
+		if err := subnet.Err(); err != nil {
+			return stacktrace.Propagate(err, "The validators don't validate subnet %s", subnet.SubnetID)
+		}
+		logrus.Infof("Verified the whitelisted validators validate subnet %s.", subnet.SubnetID)
+
+		if err := avalancheNetwork.RequireBootstrappedSubnet(definedNetwork, subnet.SubnetID.String(), subnetValidatorNames...); err != nil {
+			return stacktrace.Propagate(err, "Failed to restart the validators requiring subnet %s", subnet.SubnetID)
+		}
+		blockchainID := subnet.Blockchain(subnetBlockchainName)
+		for _, validatorName := range subnetValidatorNames {
+			bootstrapped, err := topology.Node(validatorName).GetClient().InfoAPI().IsBootstrapped(blockchainID.String())
+			if err != nil {
+				return stacktrace.Propagate(err, "Failed to check blockchain %s is bootstrapped on %s", blockchainID, validatorName)
+			}
+			if !bootstrapped {
+				return stacktrace.NewError("Node %s is available before blockchain %s is bootstrapped", validatorName, blockchainID)
+			}
+		}
+		logrus.Infof("Verified the validators requiring subnet %s are available once it's bootstrapped.", subnet.SubnetID)
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
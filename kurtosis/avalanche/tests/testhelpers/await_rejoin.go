@@ -0,0 +1,42 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package testhelpers
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+// AwaitRejoin waits for the restarted node [nodeID] to be connected to [numPeers] peers and bootstrapped on every chain
+func AwaitRejoin(network *networksavalanche.AvalancheNetwork, nodeID string, numPeers int, timeout time.Duration) error {
+	var client *avalanchegoclient.Client
+	var err error
+	for startTime := time.Now(); time.Since(startTime) < timeout; time.Sleep(time.Second) {
+		// the node is reached through the client of its new container
+		client, err = network.GetNodeClient(nodeID)
+		if err != nil {
+			return stacktrace.Propagate(err, "Node %s didn't restart", nodeID)
+		}
+		peers, err := client.InfoAPI().Peers()
+		if err != nil || len(peers) < numPeers {
+			logrus.Debugf("Node %s has %d peers out of %d", nodeID, len(peers), numPeers)
+			continue
+		}
+		bootstrapped := true
+		for _, chain := range []string{"P", "X", "C"} {
+			if ok, err := client.InfoAPI().IsBootstrapped(chain); err != nil || !ok {
+				bootstrapped = false
+				break
+			}
+		}
+		if bootstrapped {
+			return nil
+		}
+	}
+	return stacktrace.NewError("Timed out waiting for node %s to rejoin the network", nodeID)
+}
@@ -0,0 +1,132 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/chainhelper"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/wallet"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/ava-labs/avalanchego/utils/crypto"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+// Wallet funds a new key from the genesis key and moves its funds from the X Chain to the C and P Chains,
+// issuing each transaction through a different node, without any keystore user
+func Wallet(avalancheImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork := scenarios.NewBootStrappingNodeNetwork(avalancheImage)
+
+	test := func(network networks.Network) error {
+		txFee := testconstants.TxFee
+		seedAmount := testconstants.SeedAmount
+		cExportAmount := seedAmount / 2
+		pExportAmount := seedAmount / 4
+
+		avalancheNetwork := networksavalanche.Cast(network)
+		var clients []*avalanchegoclient.Client
+		for i := 1; i <= 3; i++ {
+			client, err := avalancheNetwork.GetNodeClient(fmt.Sprintf("bootstrapNode-%d", i))
+			if err != nil {
+				return stacktrace.Propagate(err, "Failed to fetch the client of bootstrapNode-%d", i)
+			}
+			clients = append(clients, client)
+		}
+
+		topology := top.New(network).
+			AddWallet("genesis", txFee, avalancheNetwork.GetGenesisConfig().FundedAddresses.PrivateKey)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+		genesisWallet, err := topology.TryWallet("genesis")
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to get the genesis wallet")
+		}
+		chainIDs := topology.GetChainIDs()
+
+		factory := crypto.FactorySECP256K1R{}
+		key, err := factory.NewPrivateKey()
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to generate the key of the new wallet")
+		}
+		newWallet, err := wallet.New(chainIDs, txFee, key.(*crypto.PrivateKeySECP256K1R))
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to create the new wallet")
+		}
+
+		sendTxID, err := genesisWallet.XSend(clients[0], chainIDs.AvaxAssetID, seedAmount, newWallet.Address())
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to fund the new wallet")
+		}
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(clients[1], sendTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept the funding tx: %s", sendTxID)
+		}
+
+		cExportTxID, err := newWallet.XExport(clients[1], chainIDs.CChainID, cExportAmount, newWallet.Address())
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to export AVAX to the C Chain")
+		}
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(clients[2], cExportTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ExportTx: %s", cExportTxID)
+		}
+		cImportTxID, err := newWallet.CImport(clients[2], chainIDs.XChainID)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to import AVAX to the C Chain")
+		}
+		if err := chainhelper.CChain().AwaitTransactionAcceptance(clients[2], cImportTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ImportTx: %s", cImportTxID)
+		}
+
+		pExportTxID, err := newWallet.XExport(clients[2], chainIDs.PlatformChainID, pExportAmount, newWallet.Address())
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to export AVAX to the P Chain")
+		}
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(clients[0], pExportTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ExportTx: %s", pExportTxID)
+		}
+		pImportTxID, err := newWallet.PImport(clients[0], chainIDs.XChainID)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to import AVAX to the P Chain")
+		}
+		if err := chainhelper.PChain().AwaitTransactionAcceptance(clients[0], pImportTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ImportTx: %s", pImportTxID)
+		}
+
+		// the balances are checked on a node that didn't issue the last transactions, wait for it to accept them
+		if err := chainhelper.XChain().AwaitTransactionAcceptance(clients[1], pExportTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ExportTx: %s on the checked node", pExportTxID)
+		}
+		if err := chainhelper.CChain().AwaitTransactionAcceptance(clients[1], cImportTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ImportTx: %s on the checked node", cImportTxID)
+		}
+		if err := chainhelper.PChain().AwaitTransactionAcceptance(clients[1], pImportTxID, constants.TimeoutDuration); err != nil {
+			return stacktrace.Propagate(err, "Failed to accept ImportTx: %s on the checked node", pImportTxID)
+		}
+
+		xBalance := seedAmount - cExportAmount - pExportAmount - 2*txFee
+		if err := chainhelper.XChain().CheckBalance(clients[1], newWallet.XAddress, "AVAX", xBalance); err != nil {
+			return stacktrace.Propagate(err, "Unexpected X Chain balance")
+		}
+		if err := chainhelper.CChain().CheckBalanceWei(clients[1], newWallet.EthAddress, chainhelper.NAVAXToWei(cExportAmount)); err != nil {
+			return stacktrace.Propagate(err, "Unexpected C Chain balance")
+		}
+		if err := chainhelper.PChain().CheckBalance(clients[1], newWallet.PAddress, pExportAmount-txFee); err != nil {
+			return stacktrace.Propagate(err, "Unexpected P Chain balance")
+		}
+		logrus.Infof("Verified the wallet balances on the X, C and P Chains.")
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
@@ -79,6 +79,10 @@ func Workflow(avalancheImage string) *runner.AvalancheTestRunner {
 		// PChain - 5k - 3k = 2k
 		topology.Node(delegatorNodeName).BecomeDelegator(totalAmount, seedAmount, stakeAmount, txFee, topology.Node(validatorNodeName).NodeID)
 
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+
 		// after setup we want to test moving amounts from P to X Chain and back
 		stakerNode := topology.Node(validatorNodeName)
 
@@ -0,0 +1,81 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tests
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/loadgen"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/scenarios"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests/testconstants"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/palantir/stacktrace"
+
+	top "github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/topology"
+)
+
+const (
+	loadWallets      = 10
+	loadTxsPerWallet = 50
+	loadTargetTPS    = 50
+)
+
+// XChainLoad issues chains of X Chain transactions across the five stakers at a target rate and verifies
+// they are all accepted, reporting the TPS and the finality latency
+func XChainLoad(avalancheImage string) *runner.AvalancheTestRunner {
+
+	definedNetwork := scenarios.NewBootStrappingNodeNetwork(avalancheImage)
+
+	test := func(network networks.Network) error {
+		avalancheNetwork := networksavalanche.Cast(network)
+		var clients []*avalanchegoclient.Client
+		for i := 1; i <= len(definedNetwork.Nodes); i++ {
+			client, err := avalancheNetwork.GetNodeClient(fmt.Sprintf("bootstrapNode-%d", i))
+			if err != nil {
+				return stacktrace.Propagate(err, "Failed to fetch the client of bootstrapNode-%d", i)
+			}
+			clients = append(clients, client)
+		}
+
+		topology := top.New(network).
+			AddGenesis("bootstrapNode-1", testconstants.GenesisUsername, testconstants.GenesisPassword)
+		if err := topology.Err(); err != nil {
+			return stacktrace.Propagate(err, "Failed to build the topology")
+		}
+
+		generator, err := loadgen.NewXChainLoadGenerator(topology.GetChainIDs(), clients, loadgen.XChainLoadConfig{
+			Wallets:      loadWallets,
+			TxsPerWallet: loadTxsPerWallet,
+			Amount:       2 * loadTxsPerWallet * testconstants.TxFee,
+			TxFee:        testconstants.TxFee,
+			TargetTPS:    loadTargetTPS,
+		})
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to create the load generator")
+		}
+		testReport := avalancheNetwork.TestReport()
+		if err := testReport.Step("prepare", func() error { return generator.Prepare(topology.Genesis()) }); err != nil {
+			return stacktrace.Propagate(err, "Failed to prepare the load")
+		}
+
+		var report *loadgen.Report
+		if err := testReport.Step("run", func() error {
+			report, err = generator.Run()
+			return err
+		}); err != nil {
+			return stacktrace.Propagate(err, "Failed to run the load")
+		}
+		report.Log()
+		testReport.AddTxs(report.Name, report.TxsReport())
+		if !report.Succeeded() {
+			return stacktrace.NewError("%d of the %d transactions were accepted", report.Accepted, loadWallets*loadTxsPerWallet)
+		}
+		return nil
+	}
+
+	return runner.NewGenericAvalancheTestRunner(definedNetwork, test, testconstants.TestTimeout, testconstants.TestSetupTimeout)
+}
@@ -4,7 +4,15 @@
 package executionavalanche
 
 type AvalancheTestsuiteArgs struct {
-	AvalanchegoImage          string `json:"avalanchegoImage"`
+	AvalanchegoImage string `json:"avalanchegoImage"`
+
+	// Image the Rolling Upgrade test starts the network with, before upgrading it node by node to the
+	// avalanchegoImage, leave empty to restart the nodes with the avalanchegoImage they already run
+	UpgradeFromImage string `json:"upgradeFromImage"`
+
+	// Path, inside the testsuite container, of a JSON or YAML network definition to run
+	// (e.g. networks/bootstrap_five_stakers.yaml), leave empty to skip the defined network test
+	NetworkDefinition string `json:"networkDefinition"`
 
 	// Indicates that this testsuite is being run as part of CI testing in Kurtosis Core
 	IsKurtosisCoreDevMode bool `json:"isKurtosisCoreDevMode"`
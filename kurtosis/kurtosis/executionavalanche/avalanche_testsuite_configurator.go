@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
 	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/testsuite"
 	testsuiteAvalanche "github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche"
 	"github.com/palantir/stacktrace"
@@ -43,7 +44,20 @@ func (t AvalancheTestsuiteConfigurator) ParseParamsAndCreateSuite(paramsJSONStr
 		return nil, stacktrace.Propagate(err, "An error occurred validating the deserialized testsuite params")
 	}
 
-	suite := testsuiteAvalanche.NewAvalancheTestsuite(args.AvalanchegoImage, args.IsKurtosisCoreDevMode)
+	var definedNetwork *networkbuilder.Network
+	if args.NetworkDefinition != "" {
+		definition, err := networkbuilder.LoadNetworkDefinition(args.NetworkDefinition)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred loading the network definition")
+		}
+
+		definedNetwork, err = definition.Build(args.AvalanchegoImage)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred building the network definition %s", args.NetworkDefinition)
+		}
+	}
+
+	suite := testsuiteAvalanche.NewAvalancheTestsuite(args.AvalanchegoImage, args.UpgradeFromImage, definedNetwork, args.IsKurtosisCoreDevMode)
 	return suite, nil
 }
 
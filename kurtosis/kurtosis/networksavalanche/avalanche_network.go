@@ -5,26 +5,41 @@ package networksavalanche
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/servicesavalanche/avalanchegonode"
 	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
 	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/services"
 	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
 )
 
 const (
 	waitForStartupTimeBetweenPolls = 20 * time.Second
 	waitForStartupMaxNumPolls      = 10
 	waitForTermination             = 30 * time.Second
+
+	// staking cert and key avalanchego generates when it's not given any
+	generatedStakingCertPath = "$HOME/.avalanchego/staking/staker.crt"
+	generatedStakingKeyPath  = "$HOME/.avalanchego/staking/staker.key"
 )
 
 type AvalancheNetwork struct {
 	networkCtx      *networks.NetworkContext
 	apiServiceImage string
 	nodes           map[services.ServiceID]*avalanchegonode.NodeAPIService
+	// a node started again gets a new service, serviceIDs counts the services created for each node
+	serviceIDs map[services.ServiceID]int
+	// genesisConfig is the genesis of the network the nodes were created from
+	genesisConfig *constants.NetworkGenesisConfig
+	// report is the TestReport of the test run on the network
+	report *TestReport
+	// nodes can be created concurrently, lock protects the nodes map and the genesis config
+	lock sync.RWMutex
 }
 
 func NewAvalancheNetwork(networkCtx *networks.NetworkContext, apiServiceImage string) *AvalancheNetwork {
@@ -32,6 +47,9 @@ func NewAvalancheNetwork(networkCtx *networks.NetworkContext, apiServiceImage st
 		networkCtx:      networkCtx,
 		apiServiceImage: apiServiceImage,
 		nodes:           map[services.ServiceID]*avalanchegonode.NodeAPIService{},
+		serviceIDs:      map[services.ServiceID]int{},
+		genesisConfig:   &constants.DefaultLocalNetGenesisConfig,
+		report:          newTestReport(),
 	}
 }
 
@@ -45,43 +63,65 @@ func Cast(network networks.Network) *AvalancheNetwork {
 
 func (network *AvalancheNetwork) CreateNodeNoCheck(definedNetwork *networkbuilder.Network, node *networkbuilder.Node) (services.ServiceID, *services.DefaultAvailabilityChecker, error) {
 	serviceID := services.ServiceID(node.ID)
-	if _, ok := network.nodes[serviceID]; ok {
+	if _, ok := network.getNode(serviceID); ok {
 		return serviceID, nil, fmt.Errorf("node with the same nodeID already exists")
 	}
 
-	configFactory := avalanchegonode.NewAvalancheGoContainerConfigFactory(definedNetwork, node, network.nodes)
-	uncastedService, _, checker, err := network.networkCtx.AddService(serviceID, configFactory)
+	if err := network.setGenesisConfig(definedNetwork); err != nil {
+		return "", nil, stacktrace.Propagate(err, "An error occurred getting the network genesis")
+	}
+
+	configFactory := avalanchegonode.NewAvalancheGoContainerConfigFactory(definedNetwork, node, network.copyNodes())
+	uncastedService, _, checker, err := network.networkCtx.AddService(network.nextServiceID(serviceID), configFactory)
 	if err != nil {
 		return "", nil, stacktrace.Propagate(err, "An error occurred adding the API service")
 	}
 
 	castedService := uncastedService.(*avalanchegonode.NodeAPIService)
-	network.nodes[serviceID] = castedService
+	// the transactions issued through the node are counted in the TestReport
+	castedService.GetNodeClient().SetTxRecorder(network.report)
+	network.setNode(serviceID, castedService)
 	return serviceID, checker.(*services.DefaultAvailabilityChecker), nil
 }
 
+// CreateNode adds the [node] to the network and waits for it to start
+// A node that fails to start stays in the network, so it can be removed with RemoveNode
+// It's safe to call concurrently
 func (network *AvalancheNetwork) CreateNode(definedNetwork *networkbuilder.Network, node *networkbuilder.Node) (services.ServiceID, error) {
-	serviceID := services.ServiceID(node.ID)
-	if _, ok := network.nodes[serviceID]; ok {
-		return serviceID, fmt.Errorf("node with the same nodeID already exists")
-	}
-
-	initializer := avalanchegonode.NewAvalancheGoContainerConfigFactory(definedNetwork, node, network.nodes)
-	uncastedService, _, checker, err := network.networkCtx.AddService(serviceID, initializer)
+	serviceID, checker, err := network.CreateNodeNoCheck(definedNetwork, node)
 	if err != nil {
-		return "", stacktrace.Propagate(err, "An error occurred adding the API service")
+		return "", err
 	}
-	if err = checker.WaitForStartup(waitForStartupTimeBetweenPolls, waitForStartupMaxNumPolls); err != nil {
+
+	startTime := time.Now()
+	if err := network.WaitForNodeStartup(node.ID, checker, waitForStartupTimeBetweenPolls, waitForStartupMaxNumPolls); err != nil {
 		return "", stacktrace.Propagate(err, "An error occurred waiting for the API service to start")
 	}
-	castedService := uncastedService.(*avalanchegonode.NodeAPIService)
-	network.nodes[serviceID] = castedService
+	logrus.Infof("Node: %s started in %v seconds", serviceID, time.Since(startTime).Seconds())
 	return serviceID, nil
 }
 
+// WaitForNodeStartup waits for the node [nodeID], created with CreateNodeNoCheck, to be available
+// polling its [checker] up to [maxNumPolls] times
+func (network *AvalancheNetwork) WaitForNodeStartup(nodeID string, checker services.AvailabilityChecker, timeBetweenPolls time.Duration, maxNumPolls int) error {
+	service, ok := network.getNode(services.ServiceID(nodeID))
+	if !ok {
+		return stacktrace.NewError("No node service with ID '%v' has been added", nodeID)
+	}
+	return network.waitForStartup(service, checker, timeBetweenPolls, maxNumPolls)
+}
+
+// waitForStartup waits for [service] to be available, the error says why it isn't
+func (network *AvalancheNetwork) waitForStartup(service *avalanchegonode.NodeAPIService, checker services.AvailabilityChecker, timeBetweenPolls time.Duration, maxNumPolls int) error {
+	if err := checker.WaitForStartup(timeBetweenPolls, maxNumPolls); err != nil {
+		return stacktrace.Propagate(err, "Node %s is not available: %s", service.GetServiceID(), service.GetUnavailableReason())
+	}
+	return nil
+}
+
 func (network *AvalancheNetwork) GetNodeClient(nodeID string) (*avalanchegoclient.Client, error) {
 	serviceID := services.ServiceID(nodeID)
-	service, found := network.nodes[serviceID]
+	service, found := network.getNode(serviceID)
 	if !found {
 		return nil, stacktrace.NewError("No API service with ID '%v' has been added", serviceID)
 	}
@@ -89,7 +129,19 @@ func (network *AvalancheNetwork) GetNodeClient(nodeID string) (*avalanchegoclien
 	return service.GetNodeClient(), nil
 }
 
+// GetNodeClients returns the clients of the running nodes, keyed by node ID
+func (network *AvalancheNetwork) GetNodeClients() map[string]*avalanchegoclient.Client {
+	clients := map[string]*avalanchegoclient.Client{}
+	for serviceID, service := range network.copyNodes() {
+		clients[string(serviceID)] = service.GetNodeClient()
+	}
+	return clients
+}
+
 func (network *AvalancheNetwork) GetClient() string {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
 	for _, service := range network.nodes {
 		if service != nil {
 			ip := service.GetIPAddress()
@@ -102,7 +154,7 @@ func (network *AvalancheNetwork) GetClient() string {
 
 func (network *AvalancheNetwork) GetIPAddress(nodeID string) (string, error) {
 	serviceID := services.ServiceID(nodeID)
-	service, found := network.nodes[serviceID]
+	service, found := network.getNode(serviceID)
 	if !found {
 		return "", stacktrace.NewError("No node service with ID '%v' has been added", serviceID)
 	}
@@ -112,11 +164,168 @@ func (network *AvalancheNetwork) GetIPAddress(nodeID string) (string, error) {
 
 func (network *AvalancheNetwork) RemoveNode(definedNetwork *networkbuilder.Network, node *networkbuilder.Node) error {
 	serviceID := services.ServiceID(node.ID)
-	if _, ok := network.nodes[serviceID]; !ok {
+	service, ok := network.getNode(serviceID)
+	if !ok {
 		return fmt.Errorf("node does not exist in the defined services")
 	}
 
 	definedNetwork.RemoveNode(node)
 
-	return network.networkCtx.RemoveService(serviceID, uint64(waitForTermination.Seconds()))
+	if err := network.networkCtx.RemoveService(service.GetServiceID(), uint64(waitForTermination.Seconds())); err != nil {
+		return stacktrace.Propagate(err, "An error occurred removing node %s", node.ID)
+	}
+	network.deleteNode(serviceID)
+	return nil
+}
+
+// StopNode gracefully stops the [node] container, keeping the node in the defined network
+// The node keeps its certs, and so its NodeID, and its database, it can be started again with StartNode
+func (network *AvalancheNetwork) StopNode(node *networkbuilder.Node) error {
+	return network.stopNode(node, waitForTermination)
+}
+
+// CrashNode kills the [node] container without letting the node shut down
+// Like with StopNode, the node can be started again with StartNode
+func (network *AvalancheNetwork) CrashNode(node *networkbuilder.Node) error {
+	return network.stopNode(node, 0)
+}
+
+// StartNode launches the stopped [node] again and waits for it to start
+// The node gets a new container, and IP address, using the certs and the database it had before it was stopped
+func (network *AvalancheNetwork) StartNode(definedNetwork *networkbuilder.Network, node *networkbuilder.Node) (services.ServiceID, error) {
+	if _, ok := definedNetwork.Nodes[node.ID]; !ok {
+		return "", stacktrace.NewError("Node %s is not part of the defined network", node.ID)
+	}
+	if !network.wasCreated(services.ServiceID(node.ID)) {
+		return "", stacktrace.NewError("Node %s was never started, it must be created with CreateNode", node.ID)
+	}
+	return network.CreateNode(definedNetwork, node)
+}
+
+// RestartNode stops the [node] and starts it again, see StopNode and StartNode
+func (network *AvalancheNetwork) RestartNode(definedNetwork *networkbuilder.Network, node *networkbuilder.Node) (services.ServiceID, error) {
+	if err := network.StopNode(node); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred stopping node %s", node.ID)
+	}
+	serviceID, err := network.StartNode(definedNetwork, node)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred starting node %s", node.ID)
+	}
+	return serviceID, nil
+}
+
+// stopNode removes the [node] container, giving it [timeout] to shut down before it's killed
+// the certs avalanchego generated for nodes that had none are copied to the node, so it keeps its NodeID
+func (network *AvalancheNetwork) stopNode(node *networkbuilder.Node, timeout time.Duration) error {
+	serviceID := services.ServiceID(node.ID)
+	service, ok := network.getNode(serviceID)
+	if !ok {
+		return stacktrace.NewError("Node %s is not running", node.ID)
+	}
+
+	if !node.HasCerts() {
+		cert, err := readServiceFile(service, generatedStakingCertPath)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred reading the staking cert of node %s", node.ID)
+		}
+		key, err := readServiceFile(service, generatedStakingKeyPath)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred reading the staking key of node %s", node.ID)
+		}
+		node.TLSCert(cert).PrivateKey(key)
+	}
+
+	if err := network.networkCtx.RemoveService(service.GetServiceID(), uint64(timeout.Seconds())); err != nil {
+		return stacktrace.Propagate(err, "An error occurred stopping node %s", node.ID)
+	}
+	network.deleteNode(serviceID)
+	logrus.Infof("Node: %s stopped", serviceID)
+	return nil
+}
+
+// readServiceFile returns the contents of the file at [path] in the [service] container
+func readServiceFile(service *avalanchegonode.NodeAPIService, path string) (string, error) {
+	exitCode, output, err := service.ExecCommand([]string{"/bin/sh", "-c", fmt.Sprintf("cat \"%s\"", path)})
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred reading %s", path)
+	}
+	if exitCode != 0 || output == nil {
+		return "", stacktrace.NewError("Reading %s exited with code %d", path, exitCode)
+	}
+	return string(*output), nil
+}
+
+// GetGenesisConfig returns the stakers, the funded key and the chain IDs of the network genesis
+func (network *AvalancheNetwork) GetGenesisConfig() *constants.NetworkGenesisConfig {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
+	return network.genesisConfig
+}
+
+func (network *AvalancheNetwork) setGenesisConfig(definedNetwork *networkbuilder.Network) error {
+	genesisConfig, err := definedNetwork.GetGenesisConfig()
+	if err != nil {
+		return err
+	}
+
+	network.lock.Lock()
+	defer network.lock.Unlock()
+
+	network.genesisConfig = genesisConfig
+	return nil
+}
+
+func (network *AvalancheNetwork) getNode(serviceID services.ServiceID) (*avalanchegonode.NodeAPIService, bool) {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
+	service, ok := network.nodes[serviceID]
+	return service, ok
+}
+
+func (network *AvalancheNetwork) setNode(serviceID services.ServiceID, service *avalanchegonode.NodeAPIService) {
+	network.lock.Lock()
+	defer network.lock.Unlock()
+
+	network.nodes[serviceID] = service
+}
+
+func (network *AvalancheNetwork) deleteNode(serviceID services.ServiceID) {
+	network.lock.Lock()
+	defer network.lock.Unlock()
+
+	delete(network.nodes, serviceID)
+}
+
+// nextServiceID returns the ID of the next service of the node [serviceID]
+// the first one is the node ID, the following ones are suffixed with the number of times the node was started
+func (network *AvalancheNetwork) nextServiceID(serviceID services.ServiceID) services.ServiceID {
+	network.lock.Lock()
+	defer network.lock.Unlock()
+
+	network.serviceIDs[serviceID]++
+	if network.serviceIDs[serviceID] == 1 {
+		return serviceID
+	}
+	return services.ServiceID(fmt.Sprintf("%s-%d", serviceID, network.serviceIDs[serviceID]))
+}
+
+func (network *AvalancheNetwork) wasCreated(serviceID services.ServiceID) bool {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
+	return network.serviceIDs[serviceID] > 0
+}
+
+// copyNodes returns a snapshot of the created nodes, safe to be read while other nodes are being created
+func (network *AvalancheNetwork) copyNodes() map[services.ServiceID]*avalanchegonode.NodeAPIService {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
+	nodes := make(map[services.ServiceID]*avalanchegonode.NodeAPIService, len(network.nodes))
+	for serviceID, service := range network.nodes {
+		nodes[serviceID] = service
+	}
+	return nodes
 }
@@ -0,0 +1,87 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networksavalanche
+
+import (
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/core_api_bindings"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/services"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// healedPartitionID is the partition of every node once the network is healed
+	healedPartitionID networks.PartitionID = "healed"
+	// unassignedPartitionID groups the nodes that weren't put in any partition
+	unassignedPartitionID networks.PartitionID = "unassigned"
+)
+
+// Partition splits the network into [partitions] of node IDs, keyed by partition name
+// Links between partitions can only be blocked: Kurtosis repartitioning doesn't support latency or packet loss,
+// and the containers lack the NET_ADMIN capability tc netem needs, so degraded links can't be simulated
+// Nodes of a partition can only reach the nodes of the same partition, the nodes in none of the [partitions]
+// are put together in their own partition
+// The test must be configured with partitioning enabled, see AvalancheTestRunner.PartitioningEnabled
+func (network *AvalancheNetwork) Partition(partitions map[string][]string) error {
+	runningNodes := network.copyNodes()
+
+	partitionServices := map[networks.PartitionID]map[services.ServiceID]bool{}
+	partitioned := map[services.ServiceID]bool{}
+	for partitionName, nodeIDs := range partitions {
+		partitionID := networks.PartitionID(partitionName)
+		if partitionID == unassignedPartitionID || partitionID == healedPartitionID {
+			return stacktrace.NewError("Partition name %s is reserved", partitionName)
+		}
+
+		partitionServices[partitionID] = map[services.ServiceID]bool{}
+		for _, nodeID := range nodeIDs {
+			serviceID := services.ServiceID(nodeID)
+			service, ok := runningNodes[serviceID]
+			if !ok {
+				return stacktrace.NewError("Node %s of partition %s is not running", nodeID, partitionName)
+			}
+			if partitioned[serviceID] {
+				return stacktrace.NewError("Node %s is in more than one partition", nodeID)
+			}
+			partitioned[serviceID] = true
+			partitionServices[partitionID][service.GetServiceID()] = true
+		}
+	}
+
+	for serviceID, service := range runningNodes {
+		if partitioned[serviceID] {
+			continue
+		}
+		if _, ok := partitionServices[unassignedPartitionID]; !ok {
+			partitionServices[unassignedPartitionID] = map[services.ServiceID]bool{}
+		}
+		partitionServices[unassignedPartitionID][service.GetServiceID()] = true
+	}
+
+	blocked := &core_api_bindings.PartitionConnectionInfo{IsBlocked: true}
+	if err := network.networkCtx.RepartitionNetwork(partitionServices, nil, blocked); err != nil {
+		return stacktrace.Propagate(err, "An error occurred partitioning the network")
+	}
+	logrus.Infof("Network partitioned: %v", partitions)
+	return nil
+}
+
+// Heal puts every node back in the same partition
+func (network *AvalancheNetwork) Heal() error {
+	runningNodes := network.copyNodes()
+
+	partitionServices := map[networks.PartitionID]map[services.ServiceID]bool{
+		healedPartitionID: {},
+	}
+	for _, service := range runningNodes {
+		partitionServices[healedPartitionID][service.GetServiceID()] = true
+	}
+	unblocked := &core_api_bindings.PartitionConnectionInfo{IsBlocked: false}
+	if err := network.networkCtx.RepartitionNetwork(partitionServices, nil, unblocked); err != nil {
+		return stacktrace.Propagate(err, "An error occurred healing the network")
+	}
+	logrus.Info("Network healed")
+	return nil
+}
@@ -0,0 +1,131 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networksavalanche
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/servicesavalanche/avalanchegonode"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/services"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+// TestVolumeMountpoint is where Kurtosis Core mounts the test volume on the testsuite container
+const TestVolumeMountpoint = "/suite-execution"
+
+// logsArchiveUnsafeChars are replaced in the node ID and image to get the filename of a logs archive
+var logsArchiveUnsafeChars = regexp.MustCompile("[^a-zA-Z0-9.-]+")
+
+// CollectLogs archives the log directory of each node of [definedNetwork], running or stopped, into [dirpath]
+// as <node id>_<NodeID>_<image>.tar.gz. The NodeID is left out if it's unknown, e.g. for a node that never started
+// Every node is collected even if some fail, the first failure is returned
+func (network *AvalancheNetwork) CollectLogs(definedNetwork *networkbuilder.Network, dirpath string) error {
+	if err := os.MkdirAll(dirpath, 0755); err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating the logs directory %s", dirpath)
+	}
+
+	var firstErr error
+	for _, node := range definedNetwork.Nodes {
+		logsDirpath := avalanchegonode.LogsDirpath(TestVolumeMountpoint, node)
+		if _, err := os.Stat(logsDirpath); os.IsNotExist(err) {
+			logrus.Infof("Node %s has no logs to collect", node.ID)
+			continue
+		}
+
+		nameParts := []string{node.ID}
+		if nodeID := network.nodeID(node); nodeID != "" {
+			nameParts = append(nameParts, nodeID)
+		}
+		nameParts = append(nameParts, node.GetImage())
+		for i, part := range nameParts {
+			nameParts[i] = logsArchiveUnsafeChars.ReplaceAllString(part, "-")
+		}
+		archiveFilepath := path.Join(dirpath, strings.Join(nameParts, "_")+".tar.gz")
+
+		if err := archiveDir(logsDirpath, archiveFilepath); err != nil {
+			logrus.Warnf("Failed to collect the logs of node %s: %v", node.ID, err)
+			if firstErr == nil {
+				firstErr = stacktrace.Propagate(err, "An error occurred collecting the logs of node %s", node.ID)
+			}
+			continue
+		}
+		logrus.Infof("Collected the logs of node %s to %s", node.ID, archiveFilepath)
+	}
+	return firstErr
+}
+
+// nodeID returns the NodeID of [node] from its certs, or from its API if it's running, "" if it's unknown
+func (network *AvalancheNetwork) nodeID(node *networkbuilder.Node) string {
+	if nodeID, err := node.GetNodeID(); err == nil {
+		return nodeID
+	}
+	service, ok := network.getNode(services.ServiceID(node.ID))
+	if !ok {
+		return ""
+	}
+	nodeID, err := service.GetNodeClient().InfoAPI().GetNodeID()
+	if err != nil {
+		return ""
+	}
+	return nodeID
+}
+
+// archiveDir writes the regular files of [dirpath], recursively, to the gzipped tarball [archiveFilepath]
+func archiveDir(dirpath string, archiveFilepath string) error {
+	archiveFile, err := os.Create(archiveFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating the archive %s", archiveFilepath)
+	}
+	defer archiveFile.Close()
+
+	gzipWriter := gzip.NewWriter(archiveFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+	walkErr := filepath.Walk(dirpath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(dirpath, filePath)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = relativePath
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		// the node may still be writing the file, only the size in the header is copied
+		if _, err := io.CopyN(tarWriter, file, header.Size); err != nil {
+			return stacktrace.Propagate(err, "An error occurred archiving %s", filePath)
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
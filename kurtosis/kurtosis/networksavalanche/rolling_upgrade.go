@@ -0,0 +1,93 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networksavalanche
+
+import (
+	"sort"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/services"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+// UpgradeStepCheck is called once a node of a rolling upgrade was upgraded and the network is healthy again
+// e.g. to issue transactions and check every node accepts them, an error stops the upgrade
+type UpgradeStepCheck func(upgradedNodeID string) error
+
+// RollingUpgrade replaces the image of the nodes [nodeIDs] by [image], one node at a time
+// Every node is restarted with the new image, keeping its certs and database (see RestartNode), then the
+// whole network must be available again before [check], if any, is run and the next node is upgraded
+// With no [nodeIDs] every running node of [definedNetwork] is upgraded, bootstrap nodes first
+// If a step fails the upgrade stops, leaving the network with both images
+func (network *AvalancheNetwork) RollingUpgrade(definedNetwork *networkbuilder.Network, image string, nodeIDs []string, check UpgradeStepCheck) error {
+	if len(nodeIDs) == 0 {
+		nodeIDs = network.upgradeOrder(definedNetwork)
+	}
+
+	for i, nodeID := range nodeIDs {
+		node, ok := definedNetwork.Nodes[nodeID]
+		if !ok {
+			return stacktrace.NewError("Node %s is not part of the defined network", nodeID)
+		}
+
+		logrus.Infof("Upgrading node %s (%d/%d) from %s to %s", nodeID, i+1, len(nodeIDs), node.GetImage(), image)
+		if err := network.StopNode(node); err != nil {
+			return stacktrace.Propagate(err, "An error occurred stopping node %s to upgrade it", nodeID)
+		}
+		node.Image(image)
+		if _, err := network.StartNode(definedNetwork, node); err != nil {
+			return stacktrace.Propagate(err, "Node %s didn't start with image %s", nodeID, image)
+		}
+
+		if err := network.waitForNetworkStartup(); err != nil {
+			return stacktrace.Propagate(err, "The network is not available after upgrading node %s", nodeID)
+		}
+
+		if check != nil {
+			if err := check(nodeID); err != nil {
+				return stacktrace.Propagate(err, "Check failed after upgrading node %s", nodeID)
+			}
+		}
+	}
+	return nil
+}
+
+// upgradeOrder returns the running nodes of [definedNetwork], the bootstrap nodes first, in their bootstrap order
+func (network *AvalancheNetwork) upgradeOrder(definedNetwork *networkbuilder.Network) []string {
+	var bootstrapNodes, otherNodes []*networkbuilder.Node
+	for nodeID, node := range definedNetwork.Nodes {
+		if _, ok := network.getNode(services.ServiceID(nodeID)); !ok {
+			continue
+		}
+		if node.IsBootstrapNode() {
+			bootstrapNodes = append(bootstrapNodes, node)
+		} else {
+			otherNodes = append(otherNodes, node)
+		}
+	}
+	sort.Slice(bootstrapNodes, func(i, j int) bool {
+		return bootstrapNodes[i].GetBootstrapNodeID() < bootstrapNodes[j].GetBootstrapNodeID()
+	})
+	sort.Slice(otherNodes, func(i, j int) bool {
+		return otherNodes[i].ID < otherNodes[j].ID
+	})
+
+	nodeIDs := make([]string, 0, len(bootstrapNodes)+len(otherNodes))
+	for _, node := range append(bootstrapNodes, otherNodes...) {
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+	return nodeIDs
+}
+
+// waitForNetworkStartup waits for every running node to be available
+func (network *AvalancheNetwork) waitForNetworkStartup() error {
+	for _, service := range network.copyNodes() {
+		checker := services.NewDefaultAvailabilityChecker(service.GetServiceID(), service)
+		if err := network.waitForStartup(service, checker, waitForStartupTimeBetweenPolls, waitForStartupMaxNumPolls); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networksavalanche
+
+import (
+	"strings"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+// WhitelistSubnet restarts the nodes [nodeIDs] of [definedNetwork] one at a time with the subnet [subnetID] whitelisted,
+// so they sync its blockchains and can validate it
+// The ID of a subnet is the ID of the tx that created it, it's only known once the network runs while avalanchego
+// reads the whitelisted subnets at startup, the nodes keep their certs and database across the restart (see RestartNode)
+func (network *AvalancheNetwork) WhitelistSubnet(definedNetwork *networkbuilder.Network, subnetID string, nodeIDs ...string) error {
+	return network.restartWithSubnet(definedNetwork, subnetID, false, nodeIDs)
+}
+
+// RequireBootstrappedSubnet is WhitelistSubnet, the restarted nodes also being available only once the blockchains
+// of the subnet [subnetID] are bootstrapped (see networkbuilder.Node.RequireBootstrappedSubnets)
+// The subnet must already have blockchains
+func (network *AvalancheNetwork) RequireBootstrappedSubnet(definedNetwork *networkbuilder.Network, subnetID string, nodeIDs ...string) error {
+	return network.restartWithSubnet(definedNetwork, subnetID, true, nodeIDs)
+}
+
+// restartWithSubnet restarts the nodes [nodeIDs] one at a time with the subnet [subnetID] whitelisted, and required
+// to be bootstrapped if [required]
+func (network *AvalancheNetwork) restartWithSubnet(definedNetwork *networkbuilder.Network, subnetID string, required bool, nodeIDs []string) error {
+	for _, nodeID := range nodeIDs {
+		node, ok := definedNetwork.Nodes[nodeID]
+		if !ok {
+			return stacktrace.NewError("Node %s is not part of the defined network", nodeID)
+		}
+		if !isWhitelisted(node, subnetID) {
+			node.WhitelistedSubnets(subnetID)
+		}
+		if required && !isRequired(node, subnetID) {
+			node.RequireBootstrappedSubnets(subnetID)
+		}
+
+		logrus.Infof("Restarting node %s with subnet %s whitelisted", nodeID, subnetID)
+		if _, err := network.RestartNode(definedNetwork, node); err != nil {
+			return stacktrace.Propagate(err, "Failed to restart node %s with subnet %s whitelisted", nodeID, subnetID)
+		}
+	}
+	return nil
+}
+
+// isWhitelisted returns true if [node] is started with the subnet [subnetID] whitelisted
+func isWhitelisted(node *networkbuilder.Node, subnetID string) bool {
+	for _, whitelistedSubnetID := range strings.Split(node.GetWhitelistedSubnets(), ",") {
+		if whitelistedSubnetID == subnetID {
+			return true
+		}
+	}
+	return false
+}
+
+// isRequired returns true if [node] is only available once the blockchains of the subnet [subnetID] are bootstrapped
+func isRequired(node *networkbuilder.Node, subnetID string) bool {
+	for _, requiredSubnetID := range node.GetRequiredSubnets() {
+		if requiredSubnetID == subnetID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networksavalanche
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
+	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/services"
+)
+
+// TestReport is the machine-readable report of a test run on an AvalancheNetwork
+// The runner fills in the durations, the outcome, the nodes and its steps, the test may add steps of its own
+// The transactions awaited with the chain helpers are recorded through the node clients, the test adds the others
+type TestReport struct {
+	lock sync.Mutex
+	// recordedTxs are the IDs of the transactions whose outcome was recorded, keyed by chain
+	recordedTxs map[string]map[string]bool
+
+	Name                 string                `json:"name"`
+	StartTime            time.Time             `json:"startTime"`
+	SetupDurationSeconds float64               `json:"setupDurationSeconds"`
+	RunDurationSeconds   float64               `json:"runDurationSeconds"`
+	Succeeded            bool                  `json:"succeeded"`
+	Error                string                `json:"error,omitempty"`
+	Steps                []*StepReport         `json:"steps"`
+	Nodes                []*NodeReport         `json:"nodes"`
+	Txs                  map[string]*TxsReport `json:"txs"`
+	ArtifactsDirpath     string                `json:"artifactsDirpath,omitempty"`
+}
+
+// StepReport is the duration and the outcome of a step of a test
+type StepReport struct {
+	Name            string  `json:"name"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// NodeReport describes a node of the network at the end of the test, a stopped node has no NodeID nor IP address
+type NodeReport struct {
+	ID        string `json:"id"`
+	NodeID    string `json:"nodeID,omitempty"`
+	Image     string `json:"image"`
+	IPAddress string `json:"ipAddress,omitempty"`
+	Running   bool   `json:"running"`
+	Byzantine bool   `json:"byzantine,omitempty"`
+}
+
+// TxsReport counts the transactions issued by a test, and their outcome
+type TxsReport struct {
+	Issued        int `json:"issued"`
+	IssueFailures int `json:"issueFailures"`
+	Accepted      int `json:"accepted"`
+	Rejected      int `json:"rejected"`
+	TimedOut      int `json:"timedOut"`
+}
+
+// TestReport returns the TestReport of the test run on the network
+func (network *AvalancheNetwork) TestReport() *TestReport {
+	return network.report
+}
+
+// ReportNodes records the nodes of [definedNetwork] in the TestReport, with the NodeID and the IP address
+// of the running ones
+func (network *AvalancheNetwork) ReportNodes(definedNetwork *networkbuilder.Network) {
+	nodes := make([]*NodeReport, 0, len(definedNetwork.Nodes))
+	for _, node := range definedNetwork.Nodes {
+		nodeReport := &NodeReport{
+			ID:        node.ID,
+			Image:     node.GetImage(),
+			Byzantine: node.IsByzantine(),
+		}
+		if service, ok := network.getNode(services.ServiceID(node.ID)); ok {
+			nodeReport.Running = true
+			nodeReport.IPAddress = service.GetIPAddress()
+			// the node may be unreachable at the end of a failed test, it's reported without its NodeID
+			if nodeID, err := service.GetNodeClient().InfoAPI().GetNodeID(); err == nil {
+				nodeReport.NodeID = nodeID
+			}
+		}
+		nodes = append(nodes, nodeReport)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	network.report.lock.Lock()
+	defer network.report.lock.Unlock()
+	network.report.Nodes = nodes
+}
+
+func newTestReport() *TestReport {
+	return &TestReport{
+		StartTime:   time.Now(),
+		Txs:         map[string]*TxsReport{},
+		recordedTxs: map[string]map[string]bool{},
+	}
+}
+
+// Step runs [step] and records its duration and its error, which is returned
+func (r *TestReport) Step(name string, step func() error) error {
+	startTime := time.Now()
+	err := step()
+
+	stepReport := &StepReport{
+		Name:            name,
+		DurationSeconds: time.Since(startTime).Seconds(),
+	}
+	if err != nil {
+		stepReport.Error = err.Error()
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.Steps = append(r.Steps, stepReport)
+	return err
+}
+
+// AddTxs adds [txs] to the transactions counted under [label], e.g. a chain name
+func (r *TestReport) AddTxs(label string, txs TxsReport) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.addTxs(label, txs)
+}
+
+// RecordTx counts the [txID] transaction issued on [chain] under the chain name, with its [outcome]
+// A transaction awaited on several nodes is counted once, with its first outcome
+func (r *TestReport) RecordTx(chain string, txID string, outcome avalanchegoclient.TxOutcome) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if txID != "" {
+		if r.recordedTxs[chain] == nil {
+			r.recordedTxs[chain] = map[string]bool{}
+		}
+		if r.recordedTxs[chain][txID] {
+			return
+		}
+		r.recordedTxs[chain][txID] = true
+	}
+
+	var txs TxsReport
+	switch outcome {
+	case avalanchegoclient.TxIssueFailed:
+		txs.IssueFailures = 1
+	case avalanchegoclient.TxAccepted:
+		txs.Issued, txs.Accepted = 1, 1
+	case avalanchegoclient.TxRejected:
+		txs.Issued, txs.Rejected = 1, 1
+	case avalanchegoclient.TxTimedOut:
+		txs.Issued, txs.TimedOut = 1, 1
+	}
+	r.addTxs(fmt.Sprintf("%s Chain", chain), txs)
+}
+
+func (r *TestReport) addTxs(label string, txs TxsReport) {
+	counts, ok := r.Txs[label]
+	if !ok {
+		counts = &TxsReport{}
+		r.Txs[label] = counts
+	}
+	counts.Issued += txs.Issued
+	counts.IssueFailures += txs.IssueFailures
+	counts.Accepted += txs.Accepted
+	counts.Rejected += txs.Rejected
+	counts.TimedOut += txs.TimedOut
+}
+
+// JSON returns the indented JSON encoding of the TestReport
+func (r *TestReport) JSON() ([]byte, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return json.MarshalIndent(r, "", "  ")
+}
@@ -0,0 +1,37 @@
+// (c) 2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networksavalanche
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
+)
+
+func TestRecordTx(t *testing.T) {
+	report := newTestReport()
+
+	report.RecordTx(avalanchegoclient.XChain, "tx-1", avalanchegoclient.TxAccepted)
+	// awaited again on another node
+	report.RecordTx(avalanchegoclient.XChain, "tx-1", avalanchegoclient.TxAccepted)
+	report.RecordTx(avalanchegoclient.XChain, "tx-2", avalanchegoclient.TxRejected)
+	report.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+	report.RecordTx(avalanchegoclient.XChain, "", avalanchegoclient.TxIssueFailed)
+	report.RecordTx(avalanchegoclient.PChain, "tx-1", avalanchegoclient.TxTimedOut)
+	// the load generators add their counts under the same label
+	report.AddTxs("X Chain", TxsReport{Issued: 10, Accepted: 10})
+
+	expected := map[string]TxsReport{
+		"X Chain": {Issued: 12, IssueFailures: 2, Accepted: 11, Rejected: 1},
+		"P Chain": {Issued: 1, TimedOut: 1},
+	}
+	if len(report.Txs) != len(expected) {
+		t.Fatalf("expected the chains %v, got %v", expected, report.Txs)
+	}
+	for label, txs := range expected {
+		if counts, ok := report.Txs[label]; !ok || *counts != txs {
+			t.Fatalf("expected %+v under %s, got %+v", txs, label, counts)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package avalanchegonode
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
@@ -9,17 +10,46 @@ import (
 	"github.com/palantir/stacktrace"
 	"github.com/sirupsen/logrus"
 	"os"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 const (
+	avalancheGoBinary    = "/avalanchego/build/avalanchego"
 	testVolumeMountpoint = "/test-volume"
 	configFileID         = "cChainConfig"
-	configFileContents   = `{"coreth-config":{"snowman-api-enabled": false,"coreth-admin-api-enabled": false,"net-api-enabled": true,"rpc-gas-cap": 2500000000,"rpc-tx-fee-cap": 100,"eth-api-enabled": true,"personal-api-enabled": true,"tx-pool-api-enabled": true,"debug-api-enabled": false,"web3-api-enabled": true,"local-txs-enabled": true}}`
+	genesisFileID        = "genesis"
+	cChainConfigKey      = "coreth-config"
+	dataDirsDirname      = "avalanchego"
+	dataDirsMountpoint   = testVolumeMountpoint + "/" + dataDirsDirname
+	logsDirname          = "logs"
 )
 
+// LogsDirpath returns where [node] writes its logs, in its data dir, given the [testVolumeDirpath] where the
+// test volume is mounted. The logs outlive the node container
+func LogsDirpath(testVolumeDirpath string, node *networkbuilder.Node) string {
+	return path.Join(testVolumeDirpath, dataDirsDirname, node.GetDataDir(), logsDirname)
+}
+
+// defaultCChainConfig is the coreth config of every node, each key can be overridden per node
+var defaultCChainConfig = map[string]interface{}{
+	"snowman-api-enabled":      false,
+	"coreth-admin-api-enabled": false,
+	"net-api-enabled":          true,
+	"rpc-gas-cap":              2500000000,
+	"rpc-tx-fee-cap":           100,
+	"eth-api-enabled":          true,
+	"personal-api-enabled":     true,
+	"tx-pool-api-enabled":      true,
+	"debug-api-enabled":        false,
+	"web3-api-enabled":         true,
+	"local-txs-enabled":        true,
+}
+
 type AvalancheGoContainerConfigFactory struct {
-	nodeConfig             *networkbuilder.Node
+	nodeConfig       *networkbuilder.Node
 	definedNetwork   *networkbuilder.Network
 	createdNodes     map[services.ServiceID]*NodeAPIService
 	bootstrapNodes   string
@@ -31,18 +61,50 @@ func NewAvalancheGoContainerConfigFactory(definedNetwork *networkbuilder.Network
 }
 
 func (factory AvalancheGoContainerConfigFactory) GetCreationConfig(containerIpAddr string) (*services.ContainerCreationConfig, error) {
+	httpPort, err := factory.nodeConfig.GetHTTPPort()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the HTTP port")
+	}
+	stakingPort, err := factory.nodeConfig.GetStakingPort()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the staking port")
+	}
+
 	serviceCreatingFunc := func(serviceCtx *services.ServiceContext) services.Service {
-		return NewNodeAPIService(serviceCtx, factory.nodeConfig.GetHTTPPort(), factory.nodeConfig.GetStakingPort())
+		requirements := AvailabilityRequirements{
+			Chains:   factory.nodeConfig.GetRequiredChains(),
+			Subnets:  factory.nodeConfig.GetRequiredSubnets(),
+			MinPeers: factory.nodeConfig.GetMinPeers(),
+		}
+		return NewNodeAPIService(serviceCtx, httpPort, stakingPort, requirements)
+	}
+
+	configFileContents, err := factory.getConfigFileContents()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred creating the config file contents")
 	}
 
 	fileGeneratingFuncs := map[string]func(*os.File) error{
 		configFileID: func(fp *os.File) error {
-			if _, err := fp.WriteString(configFileContents); err != nil {
+			if _, err := fp.Write(configFileContents); err != nil {
 				return stacktrace.Propagate(err, "An error occurred writing config file contents to fp")
 			}
 			return nil
 		},
 	}
+	if customGenesis := factory.definedNetwork.GetCustomGenesis(); customGenesis != nil {
+		genesisJSON, err := customGenesis.GetJSON()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred building the genesis")
+		}
+		fileGeneratingFuncs[genesisFileID] = func(fp *os.File) error {
+			if _, err := fp.Write(genesisJSON); err != nil {
+				return stacktrace.Propagate(err, "An error occurred writing the genesis to fp")
+			}
+			return nil
+		}
+	}
+
 	if factory.nodeConfig.HasCerts() {
 		fileGeneratingFuncs[constants.StakingTLSCertFileID] = func(fp *os.File) error {
 			if _, err := fp.Write(bytes.NewBufferString(factory.nodeConfig.GetTLSCert()).Bytes()); err != nil {
@@ -61,8 +123,8 @@ func (factory AvalancheGoContainerConfigFactory) GetCreationConfig(containerIpAd
 
 	result := services.NewContainerCreationConfigBuilder(factory.nodeConfig.GetImage(), testVolumeMountpoint, serviceCreatingFunc).
 		WithUsedPorts(map[string]bool{
-			fmt.Sprintf("%v/tcp", factory.nodeConfig.GetStakingPort()): true,
-			fmt.Sprintf("%v/tcp", factory.nodeConfig.GetHTTPPort()):    true,
+			fmt.Sprintf("%v/tcp", stakingPort): true,
+			fmt.Sprintf("%v/tcp", httpPort):    true,
 		}).
 		WithGeneratedFiles(fileGeneratingFuncs).
 		Build()
@@ -70,36 +132,62 @@ func (factory AvalancheGoContainerConfigFactory) GetCreationConfig(containerIpAd
 }
 
 func (factory AvalancheGoContainerConfigFactory) GetRunConfig(containerIpAddr string, generatedFileFilepaths map[string]string) (*services.ContainerRunConfig, error) {
-	publicIPFlag := fmt.Sprintf("--public-ip=%s", containerIpAddr)
-	commandList := []string{
-		"/avalanchego/build/avalanchego",
-		publicIPFlag,
-		"--network-id=local",
-		fmt.Sprintf("--http-port=%d", 9650),
-		"--http-host=", // Leave empty to make API openly accessible
-		fmt.Sprintf("--staking-port=%d", 9651),
-		fmt.Sprintf("--log-level=%s", "debug"),
-		fmt.Sprintf("--snow-sample-size=%d", factory.definedNetwork.GetSnowSampleSize()),
-		fmt.Sprintf("--snow-quorum-size=%d", factory.definedNetwork.GetSnowQuorumSize()),
-		fmt.Sprintf("--staking-enabled=%v", factory.nodeConfig.GetStaking()),
-		fmt.Sprintf("--tx-fee=%d", factory.definedNetwork.GetTxFee()),
+	httpPort, err := factory.nodeConfig.GetHTTPPort()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the HTTP port")
+	}
+	stakingPort, err := factory.nodeConfig.GetStakingPort()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the staking port")
 	}
 
-	if factory.nodeConfig.HasCerts() {
-		commandList = append(commandList, fmt.Sprintf("--staking-tls-cert-file=\"%s\"", generatedFileFilepaths[constants.StakingTLSCertFileID]))
-		commandList = append(commandList, fmt.Sprintf("--staking-tls-key-file=\"%s\"", generatedFileFilepaths[constants.StakingTLSKeyFileID]))
+	flags := map[string]string{
+		"--public-ip":        containerIpAddr,
+		"--network-id":       "local",
+		"--http-port":        strconv.Itoa(httpPort),
+		"--http-host":        "", // Leave empty to make API openly accessible
+		"--staking-port":     strconv.Itoa(stakingPort),
+		"--log-level":        string(factory.nodeConfig.GetLogLevel()),
+		"--snow-sample-size": strconv.Itoa(factory.nodeConfig.GetSnowSampleSize(factory.definedNetwork)),
+		"--snow-quorum-size": strconv.Itoa(factory.nodeConfig.GetSnowQuorumSize(factory.definedNetwork)),
+		"--staking-enabled":  strconv.FormatBool(factory.nodeConfig.GetStaking()),
+		"--tx-fee":           strconv.FormatUint(factory.definedNetwork.GetTxFee(), 10),
+		"--db-dir":           path.Join(dataDirsMountpoint, factory.nodeConfig.GetDataDir(), "db"),
+		"--log-dir":          LogsDirpath(testVolumeMountpoint, factory.nodeConfig),
+	}
+
+	// the timeout and the bootstrap retries are left to avalanchego's defaults unless the node sets them
+	if timeout := factory.nodeConfig.GetNetworkInitialTimeout(); timeout > 0 {
+		flags["--network-initial-timeout"] = timeout.String()
+	}
+	if attempts := factory.nodeConfig.GetBootstrapAttempts(); attempts > 0 {
+		flags["--bootstrap-retry-enabled"] = "true"
+		flags["--bootstrap-retry-max-attempts"] = strconv.Itoa(attempts)
+	}
+
+	if customGenesis := factory.definedNetwork.GetCustomGenesis(); customGenesis != nil {
+		flags["--network-id"] = strconv.FormatUint(uint64(customGenesis.GetNetworkID()), 10)
+		flags["--genesis"] = generatedFileFilepaths[genesisFileID]
+	}
+
+	if whitelistedSubnets := factory.nodeConfig.GetWhitelistedSubnets(); whitelistedSubnets != "" {
+		flags["--whitelisted-subnets"] = whitelistedSubnets
+	}
+
+	if _, ok := generatedFileFilepaths[constants.StakingTLSCertFileID]; ok {
+		flags["--staking-tls-cert-file"] = generatedFileFilepaths[constants.StakingTLSCertFileID]
+		flags["--staking-tls-key-file"] = generatedFileFilepaths[constants.StakingTLSKeyFileID]
 	}
 
 	// NOTE: This seems weird, BUT there's a reason for it: An avalanche node doesn't use certs, and instead relies on
 	//  the user explicitly passing in the node ID of the bootstrapper it wants. This prevents man-in-the-middle
 	//  attacks, just like using a cert would. Us hardcoding this bootstrapper ID here is the equivalent
 	//  of a user knowing the node ID in advance, which provides the same level of protection.
-	commandList = append(commandList, "--bootstrap-ids="+factory.nodeConfig.GetConnectedBTNodeIDs())
+	flags["--bootstrap-ids"] = factory.nodeConfig.GetConnectedBTNodeIDs()
 
 	// find the ips of the bootstrap nodes
 	bootstrapNodeID := factory.nodeConfig.GetBootstrapNodeID()
 	var joinedBootStrapNodes []string
-	var bootstrapIPs string
 	if bootstrapNodeID == 0 {
 		bootstrapNodeID = factory.definedNetwork.GetNumBootstrapNodes() + 1
 	}
@@ -107,25 +195,43 @@ func (factory AvalancheGoContainerConfigFactory) GetRunConfig(containerIpAddr st
 	for i := 1; i < bootstrapNodeID; i++ {
 		otherBootstrapNode, ok := factory.createdNodes[services.ServiceID(fmt.Sprintf("bootstrapNode-%d", i))]
 		if !ok {
-			panic(fmt.Sprintf("trying to address a bootstrap-%d node that does not exist", i))
+			return nil, stacktrace.NewError("trying to address a bootstrap-%d node that does not exist", i)
 		}
 		joinedBootStrapNodes = append(joinedBootStrapNodes, fmt.Sprintf("%s:%d", otherBootstrapNode.GetIPAddress(), otherBootstrapNode.GetStakingPort()))
 	}
-	bootstrapIPs = strings.Join(joinedBootStrapNodes, ",")
+	flags["--bootstrap-ips"] = strings.Join(joinedBootStrapNodes, ",")
 
-	commandList = append(commandList, "--bootstrap-ips="+bootstrapIPs)
-
-	// Create new command list that adds suffix to config file (otherwise viper
-	// cannot open it)
+	// Adds a suffix to the config file (otherwise viper cannot open it)
 	configFilepath, found := generatedFileFilepaths[configFileID]
 	if found {
-		commandList = append(commandList, fmt.Sprintf("--config-file=\"%s.json\"", configFilepath))
-		combinedCommandList := strings.Join(commandList, " ")
-		commandList = []string{
-			"/bin/sh",
-			"-c",
-			fmt.Sprintf("mv \"%s\" \"%s.json\" && %s", configFilepath, configFilepath, combinedCommandList),
-		}
+		flags["--config-file"] = configFilepath + ".json"
+	}
+
+	// the node flags override any of the defaults above
+	for flag, value := range factory.nodeConfig.GetFlags() {
+		flags[flag] = value
+	}
+
+	// flags are sorted so the command is the same between runs
+	flagNames := make([]string, 0, len(flags))
+	for flag := range flags {
+		flagNames = append(flagNames, flag)
+	}
+	sort.Strings(flagNames)
+
+	commandList := []string{avalancheGoBinary}
+	for _, flag := range flagNames {
+		commandList = append(commandList, fmt.Sprintf("%s=%s", flag, shellQuote(flags[flag])))
+	}
+
+	combinedCommandList := strings.Join(commandList, " ")
+	if found {
+		combinedCommandList = fmt.Sprintf("mv \"%s\" \"%s.json\" && %s", configFilepath, configFilepath, combinedCommandList)
+	}
+	commandList = []string{
+		"/bin/sh",
+		"-c",
+		combinedCommandList,
 	}
 
 	logrus.Infof("Command list for node: %v -> %v\n", factory.nodeConfig, commandList)
@@ -135,4 +241,32 @@ func (factory AvalancheGoContainerConfigFactory) GetRunConfig(containerIpAddr st
 		Build()
 
 	return result, nil
-}
\ No newline at end of file
+}
+
+// getConfigFileContents merges the node and C Chain configs of the node over the defaults
+func (factory AvalancheGoContainerConfigFactory) getConfigFileContents() ([]byte, error) {
+	cChainConfig := map[string]interface{}{}
+	for key, value := range defaultCChainConfig {
+		cChainConfig[key] = value
+	}
+	for key, value := range factory.nodeConfig.GetCChainConfig() {
+		cChainConfig[key] = value
+	}
+
+	nodeConfig := map[string]interface{}{}
+	for key, value := range factory.nodeConfig.GetConfig() {
+		nodeConfig[key] = value
+	}
+	nodeConfig[cChainConfigKey] = cChainConfig
+
+	contents, err := json.Marshal(nodeConfig)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred marshalling the config file of node %s", factory.nodeConfig.ID)
+	}
+	return contents, nil
+}
+
+// shellQuote quotes [value] so it's passed untouched as a single argument by /bin/sh
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -4,6 +4,9 @@
 package avalanchegonode
 
 import (
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/avalanchegoclient"
@@ -11,20 +14,43 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// primaryNetworkChains must be bootstrapped for any node to be available
+var primaryNetworkChains = []string{"P", "C", "X"}
+
+// AvailabilityRequirements are the conditions, on top of being healthy and having the primary network chains
+// bootstrapped, for a node to be available
+type AvailabilityRequirements struct {
+	// Chains (IDs or aliases) that must be bootstrapped
+	Chains []string
+	// Subnets whose blockchains must be bootstrapped
+	Subnets []string
+	// MinPeers is the minimum number of peers the node must be connected to
+	MinPeers int
+}
+
 type NodeAPIService struct {
-	serviceCtx		   *services.ServiceContext
-	httpPort           int
-	stakingPort        int
-	bootstrappedPChain bool
-	bootstrappedCChain bool
-	bootstrappedXChain bool
+	serviceCtx   *services.ServiceContext
+	httpPort     int
+	stakingPort  int
+	requirements AvailabilityRequirements
+	// bootstrapped caches the chains known to be bootstrapped
+	bootstrapped map[string]bool
+	// client is created once, its C Chain connections are reused by every caller
+	clientOnce sync.Once
+	client     *avalanchegoclient.Client
+
+	// lock protects unavailableReason, that's read while the node is polled
+	lock              sync.Mutex
+	unavailableReason string
 }
 
-func NewNodeAPIService(serviceCtx *services.ServiceContext, httpPort int, stakePort int) *NodeAPIService {
+func NewNodeAPIService(serviceCtx *services.ServiceContext, httpPort int, stakePort int, requirements AvailabilityRequirements) *NodeAPIService {
 	return &NodeAPIService{
-		serviceCtx: serviceCtx,
-		httpPort: httpPort,
-		stakingPort: stakePort,
+		serviceCtx:   serviceCtx,
+		httpPort:     httpPort,
+		stakingPort:  stakePort,
+		requirements: requirements,
+		bootstrapped: map[string]bool{},
 	}
 }
 
@@ -39,52 +65,110 @@ func (service *NodeAPIService) GetIPAddress() string {
 	return service.serviceCtx.GetIPAddress()
 }
 
+// IsAvailable checks the node is live (healthy) and ready (chains bootstrapped, enough peers)
+// the reason a node isn't available is logged and can be retrieved with GetUnavailableReason
 func (service *NodeAPIService) IsAvailable() bool {
-	checkClient := avalanchegoclient.NewClient(service.serviceCtx.GetIPAddress(), service.httpPort, 10*time.Second)
-
-	logrus.Infof("Node: %s -> Bootstrapped P: %v Bootstrapped C: %v Bootstrapped X: %v\n",
-		service.serviceCtx.GetServiceID(),
-		service.bootstrappedPChain,
-		service.bootstrappedCChain,
-		service.bootstrappedXChain,
-	)
-
-	if !service.bootstrappedPChain {
-		if bootstrapped, err := checkClient.InfoAPI().IsBootstrapped("P"); err != nil || !bootstrapped {
-			return false
+	reason := service.checkAvailability(service.GetNodeClient())
+	service.lock.Lock()
+	service.unavailableReason = reason
+	service.lock.Unlock()
+
+	if reason != "" {
+		logrus.Infof("Node: %s is not available: %s", service.serviceCtx.GetServiceID(), reason)
+		return false
+	}
+
+	logrus.Infof("Node: %s is available", service.serviceCtx.GetServiceID())
+	return true
+}
+
+// checkAvailability returns why the node isn't available, or an empty string if it is
+func (service *NodeAPIService) checkAvailability(checkClient *avalanchegoclient.Client) string {
+	// readiness
+	chains := append(append([]string{}, primaryNetworkChains...), service.requirements.Chains...)
+	if len(service.requirements.Subnets) > 0 {
+		subnetChains, err := service.getSubnetChains(checkClient)
+		if err != nil {
+			return fmt.Sprintf("could not get the subnets blockchains: %v", err)
 		}
-		service.bootstrappedPChain = true
+		chains = append(chains, subnetChains...)
 	}
 
-	if !service.bootstrappedCChain {
-		if bootstrapped, err := checkClient.InfoAPI().IsBootstrapped("C"); err != nil || !bootstrapped {
-			return false
+	for _, chain := range chains {
+		if service.bootstrapped[chain] {
+			continue
 		}
-		service.bootstrappedCChain = true
+		bootstrapped, err := checkClient.InfoAPI().IsBootstrapped(chain)
+		if err != nil {
+			return fmt.Sprintf("could not check chain %s is bootstrapped: %v", chain, err)
+		}
+		if !bootstrapped {
+			return fmt.Sprintf("chain %s is not bootstrapped", chain)
+		}
+		service.bootstrapped[chain] = true
 	}
 
-	if !service.bootstrappedXChain {
-		if bootstrapped, err := checkClient.InfoAPI().IsBootstrapped("X"); err != nil || !bootstrapped {
-			return false
+	if service.requirements.MinPeers > 0 {
+		peers, err := checkClient.InfoAPI().Peers()
+		if err != nil {
+			return fmt.Sprintf("could not get the peers: %v", err)
+		}
+		if len(peers) < service.requirements.MinPeers {
+			return fmt.Sprintf("connected to %d peers, expected at least %d", len(peers), service.requirements.MinPeers)
 		}
-		service.bootstrappedXChain = true
 	}
 
-	// todo we should use the health api
-	bootstrapped := service.bootstrappedPChain && service.bootstrappedCChain && service.bootstrappedXChain
-	if bootstrapped {
-		logrus.Infof("Node: %s is bootstrapped", service.serviceCtx.GetServiceID())
+	// liveness
+	health, err := checkClient.HealthReportsAPI().GetLiveness()
+	if err != nil {
+		return fmt.Sprintf("could not reach the health API: %v", err)
+	}
+	if !health.Healthy {
+		return fmt.Sprintf("failing health checks: %s", strings.Join(health.FailingChecks(), ", "))
+	}
+
+	return ""
+}
+
+// getSubnetChains returns the IDs of the blockchains validated by the required subnets
+func (service *NodeAPIService) getSubnetChains(checkClient *avalanchegoclient.Client) ([]string, error) {
+	blockchains, err := checkClient.PChainAPI().GetBlockchains()
+	if err != nil {
+		return nil, err
 	}
 
-	return bootstrapped
+	var chains []string
+	for _, subnetID := range service.requirements.Subnets {
+		found := false
+		for _, blockchain := range blockchains {
+			if blockchain.SubnetID.String() == subnetID {
+				chains = append(chains, blockchain.ID.String())
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("subnet %s has no blockchains", subnetID)
+		}
+	}
+	return chains, nil
 }
 
 // ===========================================================================================
 //                         API service-specific methods
 // ===========================================================================================
 
+// GetNodeClient returns the client of the node, the same one on every call
+// A node started again gets a new service, and so a new client
 func (service *NodeAPIService) GetNodeClient() *avalanchegoclient.Client {
-	return avalanchegoclient.NewClient(service.serviceCtx.GetIPAddress(), service.httpPort, 10*time.Second)
+	service.clientOnce.Do(func() {
+		service.client = avalanchegoclient.NewClient(service.serviceCtx.GetIPAddress(), service.httpPort, 10*time.Second)
+	})
+	return service.client
+}
+
+// ExecCommand runs [command] in the node container and returns its exit code and output
+func (service *NodeAPIService) ExecCommand(command []string) (int32, *[]byte, error) {
+	return service.serviceCtx.ExecCommand(command)
 }
 
 func (service *NodeAPIService) GetStakingPort() int {
@@ -94,3 +178,11 @@ func (service *NodeAPIService) GetStakingPort() int {
 func (service *NodeAPIService) GetHTTPPort() int {
 	return service.httpPort
 }
+
+// GetUnavailableReason returns why the node wasn't available the last time it was checked
+func (service *NodeAPIService) GetUnavailableReason() string {
+	service.lock.Lock()
+	defer service.lock.Unlock()
+
+	return service.unavailableReason
+}
@@ -4,23 +4,54 @@
 package testsuiteavalanche
 
 import (
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/tests"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/testsuiteavalanche/runner"
 	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/testsuite"
 )
 
 type AvalancheTestsuite struct {
 	image                 string
+	upgradeFromImage      string
 	datastoreServiceImage string
+	definedNetwork        *networkbuilder.Network
 	isKurtosisCoreDevMode bool
 }
 
-func NewAvalancheTestsuite(avalancheImage string, isKurtosisCoreDevMode bool) *AvalancheTestsuite {
-	return &AvalancheTestsuite{image: avalancheImage, isKurtosisCoreDevMode: isKurtosisCoreDevMode}
+// NewAvalancheTestsuite creates the testsuite, [definedNetwork] is optional and comes from a network definition file
+// [upgradeFromImage] is the image the Rolling Upgrade test upgrades from, [avalancheImage] if empty
+func NewAvalancheTestsuite(avalancheImage string, upgradeFromImage string, definedNetwork *networkbuilder.Network, isKurtosisCoreDevMode bool) *AvalancheTestsuite {
+	if upgradeFromImage == "" {
+		upgradeFromImage = avalancheImage
+	}
+	return &AvalancheTestsuite{image: avalancheImage, upgradeFromImage: upgradeFromImage, definedNetwork: definedNetwork, isKurtosisCoreDevMode: isKurtosisCoreDevMode}
 }
 
 func (suite AvalancheTestsuite) GetTests() map[string]testsuite.Test {
 	runTests := map[string]testsuite.Test{
 		"PChain WorkFlow":               tests.Workflow(suite.image),
+		"Partition":                     tests.Partition(suite.image),
+		"Assets":                        tests.Assets(suite.image),
+		"Wallet":                        tests.Wallet(suite.image),
+		"XChain Load":                   tests.XChainLoad(suite.image),
+		"CChain Load":                   tests.CChainLoad(suite.image),
+		"Byzantine Conflicts":           tests.ByzantineConflicts(suite.image),
+		"Node Restart":                  tests.NodeRestart(suite.image),
+		"Rolling Upgrade":               tests.RollingUpgrade(suite.upgradeFromImage, suite.image),
+		"Subnet":                        tests.Subnet(suite.image),
+		"Duplicate NodeID":              tests.DuplicateNodeID(suite.image),
+		"Custom Genesis":                tests.CustomGenesis(suite.image),
+	}
+
+	if suite.definedNetwork != nil {
+		runTests["Defined Network"] = tests.DefinedNetwork(suite.definedNetwork)
+	}
+
+	// the tests are named after their key, in their reports
+	for name, test := range runTests {
+		if avalancheTest, ok := test.(*runner.AvalancheTestRunner); ok {
+			avalancheTest.Name(name)
+		}
 	}
 
 	return runTests
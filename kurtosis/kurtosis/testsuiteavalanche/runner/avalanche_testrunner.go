@@ -5,9 +5,17 @@ package runner
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/consistency"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/builder/networkbuilder"
+	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/avalanche/libs/constants"
 	"github.com/ava-labs/avalanchego-kurtosis/kurtosis/kurtosis/networksavalanche"
 	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/networks"
 	"github.com/kurtosis-tech/kurtosis-libs/golang/lib/services"
@@ -16,72 +24,294 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	bootstrapWaitTimeBetweenPolls = 15 * time.Second
+	bootstrapWaitMaxNumPolls      = 10
+
+	defaultMaxParallelStartups = 4
+
+	// reportsDirpath is where the test reports are written on the test volume
+	reportsDirpath = networksavalanche.TestVolumeMountpoint + "/reports"
+	// artifactsDirpath is where the node logs of each test are collected on the test volume
+	artifactsDirpath = networksavalanche.TestVolumeMountpoint + "/artifacts"
+)
+
+// reportFilenameUnsafeChars are replaced in the test name to get the filename of its report and artifacts
+var reportFilenameUnsafeChars = regexp.MustCompile("[^a-z0-9]+")
+
 type AvalancheTestRunner struct {
-	nodeImage      string
-	definedNetwork *networkbuilder.Network
-	runnableTest   func(network networks.Network) error
-	testTimeout    time.Duration
-	setupTimeout   time.Duration
+	name                 string
+	nodeImage            string
+	definedNetwork       *networkbuilder.Network
+	runnableTest         func(network networks.Network) error
+	testTimeout          time.Duration
+	setupTimeout         time.Duration
+	maxParallelStartups  int
+	partitioningEnabled  bool
+	consistencyCheck     bool
+	collectLogsOnSuccess bool
 }
 
 func NewGenericAvalancheTestRunner(definedNetwork *networkbuilder.Network, test func(network networks.Network) error, testTimeout time.Duration, setupTimeout time.Duration) *AvalancheTestRunner {
 	return &AvalancheTestRunner{
-		definedNetwork: definedNetwork,
-		runnableTest:   test,
-		testTimeout:    testTimeout,
-		setupTimeout:   setupTimeout,
+		definedNetwork:      definedNetwork,
+		runnableTest:        test,
+		testTimeout:         testTimeout,
+		setupTimeout:        setupTimeout,
+		maxParallelStartups: defaultMaxParallelStartups,
+		consistencyCheck:    true,
 	}
 }
 
+// MaxParallelStartups sets how many non-bootstrap nodes are started at the same time during Setup
+// values below 1 start the nodes one at a time
+func (runner *AvalancheTestRunner) MaxParallelStartups(max int) *AvalancheTestRunner {
+	if max < 1 {
+		max = 1
+	}
+	runner.maxParallelStartups = max
+	return runner
+}
+
+// PartitioningEnabled lets the test partition the network, see AvalancheNetwork.Partition
+func (runner *AvalancheTestRunner) PartitioningEnabled(enabled bool) *AvalancheTestRunner {
+	runner.partitioningEnabled = enabled
+	return runner
+}
+
+// Name sets the name of the test in its report, and the filenames of its report and artifacts
+func (runner *AvalancheTestRunner) Name(name string) *AvalancheTestRunner {
+	runner.name = name
+	return runner
+}
+
+// ConsistencyCheck set to false skips the check that the running nodes agree on the state of the chains
+// once the test succeeded, e.g. for tests that leave the nodes diverged on purpose
+func (runner *AvalancheTestRunner) ConsistencyCheck(enabled bool) *AvalancheTestRunner {
+	runner.consistencyCheck = enabled
+	return runner
+}
+
+// CollectLogsOnSuccess set to true collects the node logs of the test even if it succeeded,
+// they're always collected if it fails or panics
+func (runner *AvalancheTestRunner) CollectLogsOnSuccess(enabled bool) *AvalancheTestRunner {
+	runner.collectLogsOnSuccess = enabled
+	return runner
+}
+
 func (runner *AvalancheTestRunner) Configure(builder *testsuite.TestConfigurationBuilder) {
 	setupTimeoutSecondsUint32 := uint32(runner.setupTimeout.Seconds())
 	runTimeoutSecondsUint32 := uint32(runner.testTimeout.Seconds())
 	builder.WithSetupTimeoutSeconds(setupTimeoutSecondsUint32).
-		WithRunTimeoutSeconds(runTimeoutSecondsUint32)
+		WithRunTimeoutSeconds(runTimeoutSecondsUint32).
+		WithPartitioningEnabled(runner.partitioningEnabled)
 }
 
-
 func (runner *AvalancheTestRunner) Setup(networkCtx *networks.NetworkContext) (networks.Network, error) {
+	if err := runner.definedNetwork.Validate(); err != nil {
+		return nil, stacktrace.Propagate(err, "Invalid network")
+	}
+
 	newNetwork := networksavalanche.NewAvalancheNetwork(networkCtx, runner.nodeImage)
+	startTime := time.Now()
+	err := newNetwork.TestReport().Step("setup nodes", func() error { return runner.setupNodes(newNetwork) })
+	newNetwork.TestReport().SetupDurationSeconds = time.Since(startTime).Seconds()
+	if err != nil {
+		// the test won't run, the logs are collected and the report is written with the setup failure
+		runner.collectLogs(newNetwork)
+		runner.writeReport(newNetwork, err)
+		return nil, err
+	}
+	return newNetwork, nil
+}
 
+// setupNodes launches the nodes of the defined network, the bootstrap nodes first
+func (runner *AvalancheTestRunner) setupNodes(newNetwork *networksavalanche.AvalancheNetwork) error {
 	// first setup bootstrap nodes
-	var nodeChecker []*services.DefaultAvailabilityChecker
+	// they're created in order as each one needs the IPs of the previous ones, but aren't waited on
+	nodeCheckers := map[string]*services.DefaultAvailabilityChecker{}
 	for i := 1; i <= runner.definedNetwork.GetNumBootstrapNodes(); i++ {
 		if bootstrapNode, ok := runner.definedNetwork.Nodes[fmt.Sprintf("bootstrapNode-%d", i)]; ok {
 			if bootstrapNode.IsBootstrapNode() {
 				_, checker, err := newNetwork.CreateNodeNoCheck(runner.definedNetwork, bootstrapNode)
 				if err != nil {
-					return nil, stacktrace.Propagate(err, "An error occurred creating a new Node")
+					return stacktrace.Propagate(err, "An error occurred creating a new Node")
 				}
-				nodeChecker = append(nodeChecker, checker)
+				nodeCheckers[bootstrapNode.ID] = checker
 			}
 		}
 	}
 
-	for _, checker := range nodeChecker {
-		err := checker.WaitForStartup(15*time.Second, 10)
-		if err != nil {
-			panic(err)
-		}
+	startupErrs := &startupErrors{}
+	var wg sync.WaitGroup
+	for nodeID, checker := range nodeCheckers {
+		wg.Add(1)
+		go func(nodeID string, checker *services.DefaultAvailabilityChecker) {
+			defer wg.Done()
+			startTime := time.Now()
+			if err := newNetwork.WaitForNodeStartup(nodeID, checker, bootstrapWaitTimeBetweenPolls, bootstrapWaitMaxNumPolls); err != nil {
+				startupErrs.add(nodeID, err)
+				return
+			}
+			logrus.Infof("Bootstrap node: %s started in %v seconds", nodeID, time.Since(startTime).Seconds())
+		}(nodeID, checker)
+	}
+	wg.Wait()
+	if err := startupErrs.err(); err != nil {
+		return stacktrace.Propagate(err, "An error occurred waiting for the bootstrap nodes to start")
 	}
 
+	// then start the other nodes, at most maxParallelStartups at a time
+	workers := make(chan struct{}, runner.maxParallelStartups)
 	for _, node := range runner.definedNetwork.Nodes {
-		if !node.IsBootstrapNode() {
-			_, err := newNetwork.CreateNode(runner.definedNetwork, node)
-			if err != nil {
-				return nil, stacktrace.Propagate(err, "An error occurred creating a new Node")
-			}
+		if node.IsBootstrapNode() {
+			continue
 		}
+
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(node *networkbuilder.Node) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			startTime := time.Now()
+			if _, err := newNetwork.CreateNode(runner.definedNetwork, node); err != nil {
+				startupErrs.add(node.ID, err)
+				return
+			}
+			logrus.Infof("Node: %s created and started in %v seconds", node.ID, time.Since(startTime).Seconds())
+		}(node)
+	}
+	wg.Wait()
+	if err := startupErrs.err(); err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating the nodes")
 	}
 
-	return newNetwork, nil
+	return nil
 }
 
 func (runner *AvalancheTestRunner) Run(network networks.Network) error {
+	avalancheNetwork := networksavalanche.Cast(network)
 	startTime := time.Now()
-	if err := runner.runnableTest(network); err != nil {
-		return stacktrace.Propagate(err, "An error occurred running the test")
+	defer func() {
+		// the node logs vanish with the containers, they're collected before the panic reaches Kurtosis
+		if r := recover(); r != nil {
+			avalancheNetwork.TestReport().RunDurationSeconds = time.Since(startTime).Seconds()
+			runner.collectLogs(avalancheNetwork)
+			runner.writeReport(avalancheNetwork, stacktrace.NewError("The test panicked: %v", r))
+			panic(r)
+		}
+	}()
+	err := runner.run(network)
+	avalancheNetwork.TestReport().RunDurationSeconds = time.Since(startTime).Seconds()
+	if err != nil || runner.collectLogsOnSuccess {
+		runner.collectLogs(avalancheNetwork)
+	}
+	runner.writeReport(avalancheNetwork, err)
+	if err != nil {
+		return err
 	}
 	logrus.Infof("- - - - - - - - - - - - - - - - - - - - - Test finished in %f seconds", time.Since(startTime).Seconds())
 	return nil
 }
+
+// run runs the test and checks the nodes are consistent once it succeeded, each in a step of the TestReport
+func (runner *AvalancheTestRunner) run(network networks.Network) error {
+	report := networksavalanche.Cast(network).TestReport()
+	if err := report.Step("test", func() error { return runner.runnableTest(network) }); err != nil {
+		return stacktrace.Propagate(err, "An error occurred running the test")
+	}
+	if runner.consistencyCheck {
+		fundedAddress := networksavalanche.Cast(network).GetGenesisConfig().FundedAddresses.Address
+		err := report.Step("consistency check", func() error {
+			return consistency.NewFromNetwork(network).XChainAddresses(fundedAddress).AwaitConsistency(constants.TimeoutDuration)
+		})
+		if err != nil {
+			return stacktrace.Propagate(err, "The nodes are not consistent at the end of the test")
+		}
+	}
+	return nil
+}
+
+// collectLogs archives the logs of every node of [network] to the artifacts directory of the test
+// and records it in the TestReport
+// Like the report, failing to collect the logs is logged and doesn't fail the test
+func (runner *AvalancheTestRunner) collectLogs(network *networksavalanche.AvalancheNetwork) {
+	report := network.TestReport()
+	dirpath := path.Join(artifactsDirpath, fmt.Sprintf("%s-%d", runner.filename(), report.StartTime.Unix()))
+	if err := network.CollectLogs(runner.definedNetwork, dirpath); err != nil {
+		logrus.Warnf("Failed to collect the node logs to %s: %v", dirpath, err)
+	}
+	report.ArtifactsDirpath = dirpath
+}
+
+// writeReport completes the TestReport of [network] with the outcome of the test, [err], and its nodes
+// and writes it to the reports directory of the test volume
+// The report is only for archiving, failing to write it is logged and doesn't fail the test
+func (runner *AvalancheTestRunner) writeReport(network *networksavalanche.AvalancheNetwork, err error) {
+	report := network.TestReport()
+	network.ReportNodes(runner.definedNetwork)
+	report.Name = runner.name
+	report.Succeeded = err == nil
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	reportJSON, marshalErr := report.JSON()
+	if marshalErr != nil {
+		logrus.Warnf("Failed to encode the test report: %v", marshalErr)
+		return
+	}
+	if mkdirErr := os.MkdirAll(reportsDirpath, 0755); mkdirErr != nil {
+		logrus.Warnf("Failed to create the test reports directory %s: %v", reportsDirpath, mkdirErr)
+		return
+	}
+	filepath := path.Join(reportsDirpath, fmt.Sprintf("%s-%d.json", runner.filename(), report.StartTime.Unix()))
+	if writeErr := ioutil.WriteFile(filepath, reportJSON, 0644); writeErr != nil {
+		logrus.Warnf("Failed to write the test report %s: %v", filepath, writeErr)
+		return
+	}
+	logrus.Infof("Test report written to %s", filepath)
+}
+
+// filename returns the name of the test made safe for the filenames of its report and artifacts
+func (runner *AvalancheTestRunner) filename() string {
+	name := strings.Trim(reportFilenameUnsafeChars.ReplaceAllString(strings.ToLower(runner.name), "-"), "-")
+	if name == "" {
+		return "test"
+	}
+	return name
+}
+
+// startupErrors collects the errors of nodes started concurrently
+type startupErrors struct {
+	lock sync.Mutex
+	errs map[string]error
+}
+
+func (s *startupErrors) add(nodeID string, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.errs == nil {
+		s.errs = map[string]error{}
+	}
+	s.errs[nodeID] = err
+}
+
+// err returns a single error listing every node that failed to start, or nil
+func (s *startupErrors) err() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.errs) == 0 {
+		return nil
+	}
+
+	failures := make([]string, 0, len(s.errs))
+	for nodeID, err := range s.errs {
+		failures = append(failures, fmt.Sprintf("%s: %v", nodeID, err))
+	}
+	return stacktrace.NewError("%d node(s) failed to start:\n%s", len(s.errs), strings.Join(failures, "\n"))
+}